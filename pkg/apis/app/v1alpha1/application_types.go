@@ -1,17 +1,90 @@
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ApplicationSpec defines the desired state of Application
 // +k8s:openapi-gen=true
 type ApplicationSpec struct {
+	// Paused stops the operator from changing the Application's resources,
+	// so that they can be changed by hand.
+	Paused bool `json:"paused,omitempty"`
+	// DriftPolicy is what happens when the Application's resources are
+	// changed by something other than the operator, this defaults to
+	// Correct.
+	// +kubebuilder:validation:Enum=Correct,Report
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// Suspend scales all the processes to zero, and suspends the scheduled
+	// processes, the Service and configuration are kept, and the processes
+	// are scaled back to their replicas when this is removed.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// NamePrefix is the start of the names of the resources generated for
+	// the Application, this defaults to the Application's name.  Changing
+	// this creates new resources, the old Deployments are retired, and the
+	// other old resources are removed with the Application.
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	NamePrefix string `json:"namePrefix,omitempty"`
+
 	Environment map[string]string `json:"environment,omitempty"`
 
+	// Files maps absolute paths to the content of files that are mounted
+	// into every process's container.
+	Files map[string]string `json:"files,omitempty"`
+
 	// +kubebuilder:validation:MinItems=1
 	Processes []ProcessSpec `json:"processes,omitempty"`
 
+	// Replicas replaces the replicas of the primary process, this is what the
+	// scale subresource changes, so that the Application can be scaled with
+	// kubectl scale, or by a HorizontalPodAutoscaler.
+	//
+	// This defaults to the replicas of the primary process, and is replaced
+	// when they change, or when its scaling schedules start.
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// BlueGreen deploys new revisions of the primary process alongside the
+	// running revision, and switches the Service over once all the new pods
+	// are ready.
+	BlueGreen *BlueGreenSpec `json:"blueGreen,omitempty"`
+
+	// ServiceAccount configures the ServiceAccount for the Application's
+	// pods, if this isn't provided, the namespace's default ServiceAccount is
+	// used.
+	ServiceAccount *ServiceAccountSpec `json:"serviceAccount,omitempty"`
+
+	// ImagePullSecrets are used to pull the images for all the processes.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Volumes can be mounted into the processes with their VolumeMounts.
+	Volumes []VolumeSpec `json:"volumes,omitempty"`
+
+	// Links are the names of other Applications in the namespace, the URL
+	// of each is provided in an environment variable, for example
+	// ACCOUNTS_URL for "accounts".
+	Links []string `json:"links,omitempty"`
+
+	// Addons bind backing services to the Application, from Secrets in the
+	// Service Binding layout.
+	Addons []AddonSpec `json:"addons,omitempty"`
+
+	// NetworkPolicy restricts the traffic to and from the processes' pods,
+	// by default only the ports of the processes accept connections.
+	NetworkPolicy bool `json:"networkPolicy,omitempty"`
+
+	// Release is run as a Job when the image or the configuration changes,
+	// the processes aren't updated until it succeeds.
+	Release *ReleaseSpec `json:"release,omitempty"`
+
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 }
@@ -23,22 +96,393 @@ type ProcessSpec struct {
 	// +kubebuilder:validation:Pattern=.+:.+
 	Image string `json:"image,omitempty"`
 	Port  int32  `json:"port"`
+	// Replicas can be zero to stop the process without removing it.
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Strategy is used to replace the existing pods with new ones, this
+	// defaults to a RollingUpdate, Recreate is useful for processes that
+	// must only ever have one pod running.
+	Strategy appsv1.DeploymentStrategy `json:"strategy,omitempty"`
+	// MinReadySeconds is how long a new pod must be ready for before it is
+	// considered available.
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// ProgressDeadlineSeconds is how long a rollout can take to make
+	// progress before the Application reports that it is stuck.
 	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// Canary runs a second version of the process alongside the primary
+	// pods, behind the same Service.
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// DisruptionBudget limits how many of the process's pods can be evicted
+	// at once, if this isn't provided, processes with more than one replica
+	// allow one pod to be unavailable.
+	DisruptionBudget *DisruptionBudgetSpec `json:"disruptionBudget,omitempty"`
+
+	// NodeSelector, Tolerations and Affinity are applied to the process's
+	// pods.
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+	Affinity     *corev1.Affinity    `json:"affinity,omitempty"`
+	// SpreadAcrossZones prefers to schedule the process's pods in different
+	// zones, in addition to the Affinity.
+	SpreadAcrossZones bool `json:"spreadAcrossZones,omitempty"`
+
+	// SecurityContext replaces the hardened default security context for
+	// the process's pods.
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// ContainerSecurityContext replaces the hardened default security
+	// context for the process's container.
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// VolumeMounts mount the Application's volumes into the process's
+	// container.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Sidecars are run alongside the process's container, and
+	// InitContainers are run to completion before it starts.
+	Sidecars       []ContainerSpec `json:"sidecars,omitempty"`
+	InitContainers []ContainerSpec `json:"initContainers,omitempty"`
+
+	// Ingress restricts which pods can connect to the process's port, if
+	// the Application has a NetworkPolicy.
+	Ingress *IngressPolicySpec `json:"ingress,omitempty"`
+	// Egress is the traffic that the process's pods can send, if the
+	// Application has a NetworkPolicy, if this is empty, all traffic is
+	// allowed.
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+
+	// Schedule runs the process as a CronJob instead of a Deployment, in
+	// cron format, for example "*/15 * * * *".
+	Schedule string `json:"schedule,omitempty"`
+	// ConcurrencyPolicy, SuccessfulJobsHistoryLimit and
+	// FailedJobsHistoryLimit configure the CronJob for a scheduled process.
+	// +kubebuilder:validation:Enum=Allow,Forbid,Replace
+	ConcurrencyPolicy batchv1beta1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// ScalingSchedules change the replicas of the process at the times they
+	// start, the schedule that started most recently is used, and if none
+	// have started, the process's replicas are used.
+	ScalingSchedules []ScalingSchedule `json:"scalingSchedules,omitempty"`
+}
+
+// ScalingSchedule sets the replicas of a process from the times that it
+// starts.
+// +k8s:openapi-gen=true
+type ScalingSchedule struct {
+	// Name identifies the schedule in the Application's status.
+	Name string `json:"name,omitempty"`
+	// Schedule is when the replicas start to be used, in cron format, for
+	// example "0 8 * * 1-5".
+	Schedule string `json:"schedule"`
+	// +kubebuilder:validation:Minimum=0
 	Replicas int32 `json:"replicas"`
+	// TimeZone is the name of the time zone for the schedule, for example
+	// "Europe/London", this defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// AddonSpec binds a backing service to the Application.
+//
+// The keys of the Secret are mounted as files in /bindings/<name> in every
+// process's container, and the "uri" key is provided in the <NAME>_URL
+// environment variable.
+// +k8s:openapi-gen=true
+type AddonSpec struct {
+	Name string `json:"name"`
+	// SecretName is the Secret for the binding, in the same namespace.
+	SecretName string `json:"secretName"`
+	// Env maps additional environment variable names to keys in the Secret.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// IngressPolicySpec defines the pods that can connect to a process, the
+// other processes in the Application can always connect.
+// +k8s:openapi-gen=true
+type IngressPolicySpec struct {
+	// FromIngressController allows connections from the pods in the
+	// namespaces selected by the operator's INGRESS_NAMESPACE_SELECTOR.
+	FromIngressController bool `json:"fromIngressController,omitempty"`
+	// FromApplications allows connections from the pods of the named
+	// Applications in the same namespace.
+	FromApplications []string `json:"fromApplications,omitempty"`
+	// FromNamespaces allows connections from the pods in the namespaces
+	// with matching labels.
+	FromNamespaces *metav1.LabelSelector `json:"fromNamespaces,omitempty"`
+}
+
+// ContainerSpec defines an additional container for a process.
+// +k8s:openapi-gen=true
+type ContainerSpec struct {
+	corev1.Container `json:",inline"`
+	// InheritEnvironment adds the Application's environment to the
+	// container's environment, this defaults to true.
+	InheritEnvironment *bool `json:"inheritEnvironment,omitempty"`
+}
+
+// VolumeSpec defines a volume for the Application, exactly one of the sources
+// must be provided.
+// +k8s:openapi-gen=true
+type VolumeSpec struct {
+	Name string `json:"name"`
+	// EmptyDir is scratch space that is deleted with the pod.
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	// ConfigMap and Secret mount the keys of an existing object as files.
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	Secret    *corev1.SecretVolumeSource    `json:"secret,omitempty"`
+	// PersistentVolumeClaim is created for the volume by the operator.
+	PersistentVolumeClaim *PersistentVolumeClaimSpec `json:"persistentVolumeClaim,omitempty"`
+}
+
+// DriftPolicy is what happens when an Application's resources differ from
+// the Application.
+type DriftPolicy string
+
+const (
+	// DriftCorrect reverts the changes to the resources.
+	DriftCorrect DriftPolicy = "Correct"
+	// DriftReport reports the changes, and leaves the resources alone.
+	DriftReport DriftPolicy = "Report"
+)
+
+// VolumeRetentionPolicy is what happens to a PersistentVolumeClaim when the
+// Application is deleted.
+type VolumeRetentionPolicy string
+
+const (
+	// VolumeDelete deletes the claim with the Application.
+	VolumeDelete VolumeRetentionPolicy = "Delete"
+	// VolumeRetain keeps the claim after the Application is deleted.
+	VolumeRetain VolumeRetentionPolicy = "Retain"
+)
+
+// PersistentVolumeClaimSpec defines a PersistentVolumeClaim that is created
+// for an Application's volume.
+// +k8s:openapi-gen=true
+type PersistentVolumeClaimSpec struct {
+	Size resource.Quantity `json:"size"`
+	// StorageClassName is the storage class to request, if this isn't
+	// provided, the cluster default is used.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// AccessModes defaults to ReadWriteOnce.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// RetentionPolicy is what happens to the claim when the Application is
+	// deleted, this defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete,Retain
+	RetentionPolicy VolumeRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// DisruptionBudgetSpec configures the PodDisruptionBudget for a process, only
+// one of MinAvailable and MaxUnavailable can be provided.
+// +k8s:openapi-gen=true
+type DisruptionBudgetSpec struct {
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// CanaryAction is an action to take with a canary.
+type CanaryAction string
+
+const (
+	// CanaryPromote replaces the image of the process with the canary image
+	// and removes the canary.
+	CanaryPromote CanaryAction = "Promote"
+	// CanaryAbort removes the canary, leaving the process unchanged.
+	CanaryAbort CanaryAction = "Abort"
+)
+
+// CanarySpec defines a canary for a process.
+// +k8s:openapi-gen=true
+type CanarySpec struct {
+	// +kubebuilder:validation:Pattern=.+:.+
+	Image string `json:"image"`
+	// Replicas is the number of canary pods to run in addition to the
+	// process's replicas, exactly one of Replicas and Weight must be set.
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Weight is the percentage of the process's replicas that should run the
+	// canary image instead.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+	// Action folds the canary back into the process.
+	// +kubebuilder:validation:Enum=Promote,Abort
+	Action CanaryAction `json:"action,omitempty"`
+}
+
+// ServiceAccountSpec configures the ServiceAccount for an Application.
+// +k8s:openapi-gen=true
+type ServiceAccountSpec struct {
+	// Name is an existing ServiceAccount to use, if this is empty, a
+	// ServiceAccount is created for the Application.
+	Name string `json:"name,omitempty"`
+	// Annotations are added to the created ServiceAccount, for example to
+	// associate it with a cloud provider identity.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ImagePullSecrets are added to the created ServiceAccount.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// ReleaseSpec defines a command that is run before a new version of the
+// Application is rolled out, for example to migrate a database.
+// +k8s:openapi-gen=true
+type ReleaseSpec struct {
+	// Image defaults to the image of the primary process.
+	Image string `json:"image,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// BackoffLimit is the number of retries before the release fails, this
+	// defaults to the Job default.
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// BlueGreenSpec configures blue/green deployments of the primary process.
+// +k8s:openapi-gen=true
+type BlueGreenSpec struct {
+	// ScaleDownDelaySeconds is how long the previous revision is kept
+	// running after the Service is switched, so that it can be rolled back
+	// to, this defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	ScaleDownDelaySeconds *int32 `json:"scaleDownDelaySeconds,omitempty"`
 }
 
 // ApplicationStatus defines the observed state of Application
 // +k8s:openapi-gen=true
 type ApplicationStatus struct {
+	// ObservedGeneration is the generation of the Application that was last
+	// written to its resources.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// AppliedHash is a hash of the ConfigMap, Deployments and Service that
+	// were last written for the Application.
+	AppliedHash string `json:"appliedHash,omitempty"`
+
+	// Replicas is the number of pods for the primary process, and Selector
+	// selects them, these are reported by the scale subresource.
+	Replicas int32  `json:"replicas,omitempty"`
+	Selector string `json:"selector,omitempty"`
+
+	// Conditions are the latest observations of the Application's state.
+	Conditions []ApplicationCondition `json:"conditions,omitempty"`
+
+	// BlueGreen is the state of blue/green deployments of the primary
+	// process.
+	BlueGreen *BlueGreenStatus `json:"blueGreen,omitempty"`
+
+	// Links are the URLs of the linked Applications that exist, by name.
+	Links map[string]string `json:"links,omitempty"`
+
+	// ImagePullSecret is the copy of the operator's default image pull
+	// secret that the Application's pods use.
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+
+	// Images are the running images of the processes, by digest, for
+	// example "nginx@sha256:...", by process name.
+	Images map[string]string `json:"images,omitempty"`
+
+	// Scaling are the scaling schedules that set the replicas of the
+	// processes.
+	Scaling []ScalingStatus `json:"scaling,omitempty"`
+
+	// Drift are the resources that differ from the Application, and haven't
+	// been corrected.
+	Drift []ResourceDrift `json:"drift,omitempty"`
+
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 }
 
+// ScalingStatus reports the scaling schedule that sets the replicas of a
+// process.
+// +k8s:openapi-gen=true
+type ScalingStatus struct {
+	Process string `json:"process"`
+	// Schedule is the name of the active schedule, or its cron expression if
+	// it has no name, this is empty if no schedules have started.
+	Schedule string `json:"schedule,omitempty"`
+	Replicas int32  `json:"replicas"`
+	// NextChange is when the next schedule starts.
+	NextChange *metav1.Time `json:"nextChange,omitempty"`
+}
+
+// ResourceDrift describes a resource that differs from the Application.
+// +k8s:openapi-gen=true
+type ResourceDrift struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Fields are the paths of the fields that differ, for example
+	// "spec.template.spec.containers[0].image".
+	Fields []string `json:"fields"`
+}
+
+// BlueGreenStatus records which colour of the primary process is receiving
+// traffic.
+// +k8s:openapi-gen=true
+type BlueGreenStatus struct {
+	// ActiveColour is the colour that the Service selects, "blue" or
+	// "green".
+	ActiveColour string `json:"activeColour,omitempty"`
+	// ActiveRevision identifies the pod template of the active colour.
+	ActiveRevision string `json:"activeRevision,omitempty"`
+	// SwitchedAt is when the Service was last switched between colours.
+	SwitchedAt *metav1.Time `json:"switchedAt,omitempty"`
+}
+
+// ApplicationConditionType is the type of an ApplicationCondition.
+type ApplicationConditionType string
+
+const (
+	// ApplicationReady means that all the processes are running the current
+	// version of the Application.
+	ApplicationReady ApplicationConditionType = "Ready"
+	// ApplicationRolloutStuck means that the Deployment for at least one
+	// process has exceeded its progress deadline.
+	ApplicationRolloutStuck ApplicationConditionType = "RolloutStuck"
+	// ApplicationInvalid means that the Application can't be deployed as it
+	// is configured, the message explains why.
+	ApplicationInvalid ApplicationConditionType = "Invalid"
+	// ApplicationReleaseFailed means that the release Job for the current
+	// version of the Application failed, and the processes weren't updated.
+	ApplicationReleaseFailed ApplicationConditionType = "ReleaseFailed"
+	// ApplicationLinkMissing means that at least one of the linked
+	// Applications doesn't exist.
+	ApplicationLinkMissing ApplicationConditionType = "LinkMissing"
+	// ApplicationPaused means that the Application's resources are not
+	// being reconciled.
+	ApplicationPaused ApplicationConditionType = "Paused"
+	// ApplicationSuspended means that the Application's processes are
+	// scaled to zero.
+	ApplicationSuspended ApplicationConditionType = "Suspended"
+)
+
+// ApplicationCondition describes the state of an Application at a point in
+// time.
+// +k8s:openapi-gen=true
+type ApplicationCondition struct {
+	Type   ApplicationConditionType `json:"type"`
+	Status corev1.ConditionStatus   `json:"status"`
+	// LastTransitionTime is when the condition last changed status.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase reason for the last transition.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable explanation of the last transition.
+	Message string `json:"message,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Application is the Schema for the applications API
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 type Application struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplicationTemplateSpec defines the desired state of ApplicationTemplate
+// +k8s:openapi-gen=true
+type ApplicationTemplateSpec struct {
+	// Labels are added to the generated Applications.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Spec is the base for the spec of the generated Applications.
+	Spec ApplicationSpec `json:"spec"`
+
+	// Overlays change the base spec for the targets that use them.
+	Overlays []ApplicationOverlay `json:"overlays,omitempty"`
+
+	// Targets are the namespaces to generate an Application in, the
+	// Applications have the same name as the template.
+	// +kubebuilder:validation:MinItems=1
+	Targets []ApplicationTarget `json:"targets"`
+}
+
+// ApplicationOverlay is a named set of changes to the base spec of an
+// ApplicationTemplate.
+// +k8s:openapi-gen=true
+type ApplicationOverlay struct {
+	Name string `json:"name"`
+	// Environment is merged into the base environment.
+	Environment map[string]string `json:"environment,omitempty"`
+	// Processes change the processes in the base spec with the same names.
+	Processes []ProcessOverlay `json:"processes,omitempty"`
+}
+
+// ProcessOverlay changes a process in the base spec of an
+// ApplicationTemplate.
+// +k8s:openapi-gen=true
+type ProcessOverlay struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Tag replaces the tag of the process's image.
+	Tag string `json:"tag,omitempty"`
+}
+
+// ApplicationTarget is a namespace to generate an Application in.
+// +k8s:openapi-gen=true
+type ApplicationTarget struct {
+	Namespace string `json:"namespace"`
+	// Overlay is the name of the overlay to apply to the base spec, if this
+	// is empty, the base spec is used unchanged.
+	Overlay string `json:"overlay,omitempty"`
+}
+
+// ApplicationTemplateStatus defines the observed state of ApplicationTemplate
+// +k8s:openapi-gen=true
+type ApplicationTemplateStatus struct {
+	// Applications are the generated Applications, as "namespace/name".
+	Applications []string `json:"applications,omitempty"`
+
+	// Conditions are the latest observations of the template's state.
+	Conditions []ApplicationCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationTemplate is the Schema for the applicationtemplates API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+type ApplicationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationTemplateSpec   `json:"spec,omitempty"`
+	Status ApplicationTemplateStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationTemplateList contains a list of ApplicationTemplate
+type ApplicationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ApplicationTemplate{}, &ApplicationTemplateList{})
+}
@@ -0,0 +1,102 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PromotionSpec defines the desired state of Promotion
+// +k8s:openapi-gen=true
+type PromotionSpec struct {
+	// Source is the Application to copy the images from, if it's in another
+	// namespace, it must list the Promotion's namespace in its
+	// app.bigkevmcd.com/promote-to annotation.
+	Source ApplicationReference `json:"source"`
+	// Target is the Application to promote the images to, it must be in the
+	// Promotion's namespace.
+	Target ApplicationReference `json:"target"`
+
+	// EnvironmentKeys are copied from the Source's environment to the
+	// Target's with the images.
+	EnvironmentKeys []string `json:"environmentKeys,omitempty"`
+
+	// TimeoutSeconds is how long the Target has to become Ready before the
+	// promotion fails, this defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ApplicationReference identifies an Application.
+// +k8s:openapi-gen=true
+type ApplicationReference struct {
+	Name string `json:"name"`
+	// Namespace defaults to the namespace of the referring object.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PromotionResult is the outcome of promoting images to the Target.
+type PromotionResult string
+
+const (
+	// PromotionSucceeded means that the Target became Ready with the
+	// promoted images.
+	PromotionSucceeded PromotionResult = "Succeeded"
+	// PromotionFailed means that the Target didn't become Ready with the
+	// promoted images.
+	PromotionFailed PromotionResult = "Failed"
+)
+
+// PromotionRecord records a promotion of images to the Target.
+// +k8s:openapi-gen=true
+type PromotionRecord struct {
+	// Images are the images that were promoted, by process name.
+	Images map[string]string `json:"images"`
+	// Environment are the environment variables that were promoted.
+	Environment map[string]string `json:"environment,omitempty"`
+	// TargetGeneration is the generation of the Target with the promoted
+	// images.
+	TargetGeneration int64 `json:"targetGeneration"`
+
+	StartedAt   metav1.Time  `json:"startedAt"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+	// Result is empty while the promotion is in progress.
+	Result  PromotionResult `json:"result,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+// PromotionStatus defines the observed state of Promotion
+// +k8s:openapi-gen=true
+type PromotionStatus struct {
+	// Current is the promotion that is in progress.
+	Current *PromotionRecord `json:"current,omitempty"`
+	// History are the completed promotions, the most recent first.
+	History []PromotionRecord `json:"history,omitempty"`
+	// Message explains why images can't be promoted.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Promotion is the Schema for the promotions API, it promotes the running
+// images of one Application to another, whenever they change.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type Promotion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PromotionSpec   `json:"spec,omitempty"`
+	Status PromotionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PromotionList contains a list of Promotion
+type PromotionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Promotion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Promotion{}, &PromotionList{})
+}
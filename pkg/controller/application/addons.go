@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	// bindingsRoot is where the addon Secrets are mounted, this is provided
+	// to the processes in SERVICE_BINDING_ROOT.
+	bindingsRoot      = "/bindings"
+	bindingsRootEnv   = "SERVICE_BINDING_ROOT"
+	addonURIKey       = "uri"
+	addonVolumePrefix = "addon-"
+)
+
+// makeAddonVolumes returns a volume for each of the Application's addons.
+func makeAddonVolumes(app *appv1alpha1.Application) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, addon := range app.Spec.Addons {
+		volumes = append(volumes, corev1.Volume{
+			Name: addonVolumePrefix + addon.Name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: addon.SecretName},
+			},
+		})
+	}
+	return volumes
+}
+
+// makeAddonVolumeMounts returns the mounts for the addon Secrets in the
+// bindings root.
+func makeAddonVolumeMounts(app *appv1alpha1.Application) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, addon := range app.Spec.Addons {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      addonVolumePrefix + addon.Name,
+			MountPath: filepath.Join(bindingsRoot, addon.Name),
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
+
+// makeAddonEnv returns the environment variables for the Application's
+// addons, sorted by name.
+//
+// The variables aren't provided if the Application's environment already
+// has a variable with the same name.
+func makeAddonEnv(app *appv1alpha1.Application) []corev1.EnvVar {
+	env := environmentForApp(app)
+	vars := map[string]corev1.EnvVar{}
+	for _, addon := range app.Spec.Addons {
+		optional := true
+		vars[linkEnvName(addon.Name)] = makeSecretEnvVar(linkEnvName(addon.Name), addon.SecretName, addonURIKey, &optional)
+		for name, key := range addon.Env {
+			vars[name] = makeSecretEnvVar(name, addon.SecretName, key, nil)
+		}
+	}
+	names := []string{}
+	for name := range vars {
+		if _, ok := env[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := []corev1.EnvVar{}
+	for _, name := range names {
+		result = append(result, vars[name])
+	}
+	return result
+}
+
+func makeSecretEnvVar(name, secretName, key string, optional *bool) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+				Optional:             optional,
+			},
+		},
+	}
+}
+
+// checkAddons returns true if the Secrets for all the Application's addons
+// exist, if they don't, the Application isn't Ready.
+func (r *ReconcileApplication) checkAddons(a *appv1alpha1.Application) (bool, error) {
+	missing := []string{}
+	for _, addon := range a.Spec.Addons {
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: addon.SecretName, Namespace: a.Namespace}, &corev1.Secret{})
+		if errors.IsNotFound(err) {
+			missing = append(missing, addon.SecretName)
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	if len(missing) == 0 {
+		return true, nil
+	}
+	setCondition(&a.Status, appv1alpha1.ApplicationReady, corev1.ConditionFalse, "AddonSecretsMissing",
+		fmt.Sprintf("addon Secrets not found: %s", strings.Join(missing, ", ")))
+	return false, nil
+}
+
+// bindingApplications returns a mapper from a Secret to the Applications in
+// the same namespace that have an addon with it, so that they are reconciled
+// when it's created.
+func bindingApplications(cl client.Client) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		apps := &appv1alpha1.ApplicationList{}
+		err := cl.List(context.TODO(), client.InNamespace(o.Meta.GetNamespace()), apps)
+		if err != nil {
+			log.Error(err, "failed to list Applications", "Namespace", o.Meta.GetNamespace())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, app := range apps.Items {
+			for _, addon := range app.Spec.Addons {
+				if addon.SecretName == o.Meta.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: app.Name, Namespace: app.Namespace},
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMakePodSpecWithAddons(t *testing.T) {
+	app := makeAddonsApplication()
+
+	s := makePodSpec(app, testProcess)
+
+	wantedVolumes := []corev1.Volume{
+		{
+			Name: "addon-database",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "database-binding"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(s.Volumes, wantedVolumes) {
+		t.Fatalf("makePodSpec() got Volumes %#v, wanted %#v", s.Volumes, wantedVolumes)
+	}
+	wantedMounts := []corev1.VolumeMount{
+		{Name: "addon-database", MountPath: "/bindings/database", ReadOnly: true},
+	}
+	if m := s.Containers[0].VolumeMounts; !reflect.DeepEqual(m, wantedMounts) {
+		t.Fatalf("makePodSpec() got VolumeMounts %#v, wanted %#v", m, wantedMounts)
+	}
+	optional := true
+	wantedEnv := []corev1.EnvVar{
+		makeSecretEnvVar("DATABASE_URL", "database-binding", "uri", &optional),
+		makeSecretEnvVar("PGPASSWORD", "database-binding", "password", nil),
+	}
+	if env := makeAddonEnv(app); !reflect.DeepEqual(env, wantedEnv) {
+		t.Fatalf("makeAddonEnv() got %#v, wanted %#v", env, wantedEnv)
+	}
+}
+
+func TestMakeAddonEnvDoesNotOverrideEnvironment(t *testing.T) {
+	app := makeAddonsApplication()
+	app.Spec.Environment = map[string]string{"DATABASE_URL": "postgres://localhost"}
+
+	env := makeAddonEnv(app)
+
+	for _, v := range env {
+		if v.Name == "DATABASE_URL" {
+			t.Fatalf("makeAddonEnv() got %#v, wanted no DATABASE_URL", v)
+		}
+	}
+}
+
+func TestConfigMapFromApplicationWithAddons(t *testing.T) {
+	cm := configMapFromApplication(makeAddonsApplication())
+
+	if v := cm.Data[bindingsRootEnv]; v != bindingsRoot {
+		t.Fatalf("configMapFromApplication() got %s %q, wanted %q", bindingsRootEnv, v, bindingsRoot)
+	}
+}
+
+func TestReconcileWaitsForAddonSecrets(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeAddonsApplication())
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationReady, corev1.ConditionFalse)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "database-binding", Namespace: testNamespace},
+		Data:       map[string][]byte{"uri": []byte("postgres://db")},
+	}
+	fatalIfError(t, "failed to create secret", cl.Create(context.TODO(), secret))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+}
+
+func makeAddonsApplication() *appv1alpha1.Application {
+	app := makeTestApplication()
+	app.Spec.Addons = []appv1alpha1.AddonSpec{
+		{
+			Name:       "database",
+			SecretName: "database-binding",
+			Env:        map[string]string{"PGPASSWORD": "password"},
+		},
+	}
+	return app
+}
@@ -2,12 +2,22 @@ package application
 
 import (
 	"context"
+	"os"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -24,6 +34,10 @@ import (
 
 var log = logf.Log.WithName("controller_application")
 
+// privilegedNamespacesEnvVar is a comma-separated list of namespaces where
+// Applications can have privileged containers.
+const privilegedNamespacesEnvVar = "PRIVILEGED_NAMESPACES"
+
 // Add creates a new Application Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -32,7 +46,25 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler.
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileApplication{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileApplication{
+		client:               mgr.GetClient(),
+		scheme:               mgr.GetScheme(),
+		privilegedNamespaces: parseNamespaces(os.Getenv(privilegedNamespacesEnvVar)),
+		defaultPullSecret:    parseNamespacedName(os.Getenv(defaultPullSecretEnvVar)),
+		ingressNamespaces:    parseLabelSelector(os.Getenv(ingressNamespaceSelectorEnvVar)),
+		recorder:             mgr.GetRecorder("application-controller"),
+	}
+}
+
+// parseNamespaces parses a comma-separated list of namespaces.
+func parseNamespaces(s string) map[string]bool {
+	namespaces := map[string]bool{}
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces[ns] = true
+		}
+	}
+	return namespaces
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler.
@@ -47,8 +79,22 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(&source.Kind{Type: &appv1alpha1.Application{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: linkingApplications(mgr.GetClient()),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: bindingApplications(mgr.GetClient()),
+	})
+	if err != nil {
+		return err
+	}
+
 	// TODO: find out how to test this.
-	watchedTypes := []runtime.Object{&corev1.ConfigMap{}, &appsv1.Deployment{}, &corev1.Service{}}
+	watchedTypes := []runtime.Object{&corev1.ConfigMap{}, &appsv1.Deployment{}, &corev1.Service{}, &policyv1beta1.PodDisruptionBudget{}, &corev1.ServiceAccount{}, &corev1.PersistentVolumeClaim{}, &batchv1.Job{}, &batchv1beta1.CronJob{}, &networkingv1.NetworkPolicy{}}
 	for _, t := range watchedTypes {
 		err = c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestForOwner{
 			IsController: true,
@@ -65,6 +111,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 type ReconcileApplication struct {
 	client client.Client
 	scheme *runtime.Scheme
+
+	privilegedNamespaces map[string]bool
+	defaultPullSecret    *types.NamespacedName
+	ingressNamespaces    *metav1.LabelSelector
+
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a Application object and makes
@@ -83,22 +135,203 @@ func (r *ReconcileApplication) Reconcile(request reconcile.Request) (reconcile.R
 		return reconcile.Result{}, err
 	}
 
+	status := application.Status.DeepCopy()
+	res, err := r.reconcileApplication(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return res, r.updateStatus(application, status)
+}
+
+// reconcileApplication creates or updates the resources for the Application,
+// recording the state in the Application's status.
+func (r *ReconcileApplication) reconcileApplication(application *appv1alpha1.Application, reqLogger logr.Logger) (reconcile.Result, error) {
+	// While paused, only the status is written, so that the resources can be
+	// changed by hand.
+	if application.Spec.Paused {
+		return reconcile.Result{}, r.pauseApplication(application, reqLogger)
+	}
+	err := r.resumeApplication(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.validate(application)
+	if err != nil {
+		reqLogger.Info("Application is invalid", "Reason", err.Error())
+		setCondition(&application.Status, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue, "ValidationFailed", err.Error())
+		setCondition(&application.Status, appv1alpha1.ApplicationReady, corev1.ConditionFalse, "Invalid", "")
+		return reconcile.Result{}, nil
+	}
+	setCondition(&application.Status, appv1alpha1.ApplicationInvalid, corev1.ConditionFalse, "Validated", "")
+
+	err = r.applyCanaryActions(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	err = r.mirrorReplicas(application, now, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.reconcileServiceAccount(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, r.stopOnConflict(application, err)
+	}
+
+	err = r.reconcileDefaultPullSecret(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.resolveLinks(application)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.reconcileDrift(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	err = r.createOrUpdateConfigMap(application, reqLogger)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	err = r.createOrUpdateDeployment(application, reqLogger)
+	err = r.reconcilePersistentVolumeClaims(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, r.stopOnConflict(application, err)
+	}
+
+	// The addon Secrets are watched, so this is reconciled again when they
+	// are created.
+	bound, err := r.checkAddons(application)
+	if err != nil || !bound {
+		return reconcile.Result{}, err
+	}
+
+	// The release Job is watched, so this is reconciled again when it
+	// finishes.
+	released, err := r.reconcileRelease(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !released {
+		setCondition(&application.Status, appv1alpha1.ApplicationReady, corev1.ConditionFalse, "ReleaseNotComplete", "")
+		return reconcile.Result{}, nil
+	}
+
+	// The processes are reconciled again when the next scaling schedule
+	// starts.
+	scalingDelay := updateScalingStatus(application, now)
+	updateSuspendedStatus(application)
+
+	migrating := false
+	for _, d := range deploymentsFromApplication(application) {
+		replaced, err := r.createOrUpdateDeployment(application, d, reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		migrating = migrating || replaced
+	}
+
+	err = r.reconcileCronJobs(application, reqLogger)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	requeueAfter, err := r.reconcileBlueGreen(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.reconcilePodDisruptionBudgets(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.reconcileNetworkPolicies(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.updateRolloutStatus(application)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.updateReadyStatus(application)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.updateImageStatus(application)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.updateScaleStatus(application)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	retiring, err := r.retireDeployments(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.retireConfigMaps(application, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The Service isn't updated until the pods for the old Deployments have
+	// gone, as they may not match the new selector.
+	if migrating || retiring {
+		return reconcile.Result{RequeueAfter: migrationRequeueDelay}, nil
+	}
+
 	err = r.createOrUpdateService(application, reqLogger)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	application.Status.ObservedGeneration = application.Generation
+	application.Status.AppliedHash = appliedHash(application)
 
-	return reconcile.Result{}, err
+	return reconcile.Result{RequeueAfter: shortestDelay(requeueAfter, scalingDelay)}, err
+}
+
+// validate returns an error if the Application can't be deployed as it is
+// configured.
+func (r *ReconcileApplication) validate(a *appv1alpha1.Application) error {
+	validations := []func(*appv1alpha1.Application) error{
+		r.validateSecurity,
+		validateSchedules,
+		validateScalingSchedules,
+		validateVolumes,
+		validateEnvironment,
+		validateDisruptionBudgets,
+		validateCanaries,
+		r.validateNetworkPolicy,
+	}
+	for _, v := range validations {
+		if err := v(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateStatus writes the Application's status if it has changed from the
+// original.
+func (r *ReconcileApplication) updateStatus(a *appv1alpha1.Application, original *appv1alpha1.ApplicationStatus) error {
+	if equality.Semantic.DeepEqual(&a.Status, original) {
+		return nil
+	}
+	return r.client.Status().Update(context.TODO(), a)
 }
 
 func (r *ReconcileApplication) createOrUpdateConfigMap(a *appv1alpha1.Application, logger logr.Logger) error {
@@ -121,6 +354,10 @@ func (r *ReconcileApplication) createOrUpdateConfigMap(a *appv1alpha1.Applicatio
 		return err
 	}
 
+	if driftIgnored(a, "ConfigMap", configMap.Name) {
+		logger.Info("Not updating drifted ConfigMap", "Namespace", configMap.Namespace, "Name", configMap.Name)
+		return nil
+	}
 	logger.Info("Updating existing ConfigMap", "Updated.Namespace", configMap.Namespace, "Updated.Name", configMap.Name)
 	found.Data = configMap.Data
 	err = r.client.Update(context.TODO(), found)
@@ -130,11 +367,12 @@ func (r *ReconcileApplication) createOrUpdateConfigMap(a *appv1alpha1.Applicatio
 	return r.client.Status().Update(context.TODO(), configMap)
 }
 
-func (r *ReconcileApplication) createOrUpdateDeployment(a *appv1alpha1.Application, logger logr.Logger) error {
-	deployment := deploymentFromApplication(a)
+// createOrUpdateDeployment returns true if the existing Deployment had to be
+// deleted so that it can be replaced.
+func (r *ReconcileApplication) createOrUpdateDeployment(a *appv1alpha1.Application, deployment *appsv1.Deployment, logger logr.Logger) (bool, error) {
 	err := controllerutil.SetControllerReference(a, deployment, r.scheme)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	found := &appsv1.Deployment{}
@@ -143,20 +381,26 @@ func (r *ReconcileApplication) createOrUpdateDeployment(a *appv1alpha1.Applicati
 		logger.Info("Creating a new Deployment", "Created.Namespace", deployment.Namespace, "Created.Name", deployment.Name)
 		err = r.client.Create(context.TODO(), deployment)
 		if err != nil {
-			return err
+			return false, err
 		}
-		return r.client.Status().Update(context.TODO(), deployment)
+		return false, r.client.Status().Update(context.TODO(), deployment)
 	} else if err != nil {
-		return err
+		return false, err
+	}
+
+	if !selectorsCompatible(found, deployment) {
+		return true, r.replaceDeployment(found, logger)
 	}
 
+	if driftIgnored(a, "Deployment", deployment.Name) {
+		logger.Info("Not updating drifted Deployment", "Namespace", deployment.Namespace, "Name", deployment.Name)
+		return false, nil
+	}
 	logger.Info("Updating existing Deployment", "Updated.Namespace", deployment.Namespace, "Updated.Name", deployment.Name)
+	found.Labels = deployment.Labels
+	found.Annotations = mergeAnnotations(found.Annotations, deployment.Annotations)
 	found.Spec = deployment.Spec
-	err = r.client.Update(context.TODO(), found)
-	if err != nil {
-		return err
-	}
-	return r.client.Status().Update(context.TODO(), deployment)
+	return false, r.client.Update(context.TODO(), found)
 }
 
 func (r *ReconcileApplication) createOrUpdateService(a *appv1alpha1.Application, logger logr.Logger) error {
@@ -179,7 +423,19 @@ func (r *ReconcileApplication) createOrUpdateService(a *appv1alpha1.Application,
 		return err
 	}
 
+	if driftIgnored(a, "Service", service.Name) {
+		logger.Info("Not updating drifted Service", "Namespace", service.Namespace, "Name", service.Name)
+		return nil
+	}
 	logger.Info("Updating existing Service", "Updated.Namespace", service.Namespace, "Updated.Name", service.Name)
+	// The ClusterIP can't be changed, and the NodePorts were allocated when
+	// the Service was created.
+	service.Spec.ClusterIP = found.Spec.ClusterIP
+	for i := range service.Spec.Ports {
+		if i < len(found.Spec.Ports) {
+			service.Spec.Ports[i].NodePort = found.Spec.Ports[i].NodePort
+		}
+	}
 	found.Spec = service.Spec
 	err = r.client.Update(context.TODO(), found)
 	if err != nil {
@@ -187,3 +443,18 @@ func (r *ReconcileApplication) createOrUpdateService(a *appv1alpha1.Application,
 	}
 	return r.client.Status().Update(context.TODO(), service)
 }
+
+// mergeAnnotations adds the annotations from src to dst, preserving the
+// annotations that other controllers have added to dst.
+func mergeAnnotations(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
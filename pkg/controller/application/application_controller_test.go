@@ -7,8 +7,12 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -19,9 +23,10 @@ import (
 var _ reconcile.Reconciler = &ReconcileApplication{}
 
 const (
-	testNamespace = "testing"
-	testAppName   = "test-application"
-	testReplicas  = 5
+	testNamespace      = "testing"
+	testAppName        = "test-application"
+	testDeploymentName = testAppName + "-web"
+	testReplicas       = 5
 )
 
 func TestCreateUnknownApplicationConfiguration(t *testing.T) {
@@ -55,11 +60,11 @@ func TestCreateUnknownApplicationDeployment(t *testing.T) {
 	}
 
 	dp := &appsv1.Deployment{}
-	err = cl.Get(context.TODO(), ns(testAppName, testNamespace), dp)
+	err = cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), dp)
 	if err != nil {
 		t.Fatalf("failed to get created deployment: %s", err)
 	}
-	assertDeploymentConfiguration(t, testAppName, testNamespace, cl, testReplicas)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
 }
 
 func TestCreateUnknownApplicationService(t *testing.T) {
@@ -96,34 +101,176 @@ func TestUpdateExistingConfiguration(t *testing.T) {
 func TestUpdateExistingDeployment(t *testing.T) {
 	app := makeTestApplication()
 	app.Spec.Processes[0].Replicas = 2
-	r, cl := createApplicationReconciler(t, app, deploymentFromApplication(makeTestApplication()))
+	r, cl := createApplicationReconciler(t, app, deploymentFromProcess(makeTestApplication(), testProcess))
 	req := makeRequest()
 
 	_, err := r.Reconcile(req)
 
 	fatalIfError(t, "failed to reconcile", err)
-	assertDeploymentConfiguration(t, testAppName, testNamespace, cl, 2)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 2)
+}
+
+func TestMigrateFromApplicationDeployment(t *testing.T) {
+	app := makeTestApplication()
+	legacy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            testAppName,
+			Namespace:       testNamespace,
+			Labels:          selectorLabelsForApp(app),
+			OwnerReferences: []metav1.OwnerReference{makeOwnerReference(app)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selectorLabelsForApp(app)},
+		},
+	}
+	r, cl := createApplicationReconciler(t, app, legacy)
+	req := makeRequest()
+
+	res, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	if res.RequeueAfter != migrationRequeueDelay {
+		t.Fatalf("res.RequeueAfter got %v, wanted %v", res.RequeueAfter, migrationRequeueDelay)
+	}
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	fatalIfError(t, "failed to get legacy deployment", cl.Get(context.TODO(), ns(testAppName, testNamespace), &appsv1.Deployment{}))
+
+	markDeploymentReady(t, cl, testDeploymentName)
+	res, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	if res.RequeueAfter != 0 {
+		t.Fatalf("res.RequeueAfter got %v, wanted 0", res.RequeueAfter)
+	}
+	assertNotFound(t, cl, ns(testAppName, testNamespace), &appsv1.Deployment{})
+}
+
+func TestReplaceDeploymentWithIncompatibleSelector(t *testing.T) {
+	app := makeTestApplication()
+	existing := deploymentFromProcess(app, testProcess)
+	existing.OwnerReferences = []metav1.OwnerReference{makeOwnerReference(app)}
+	existing.Spec.Selector = &metav1.LabelSelector{MatchLabels: selectorLabelsForApp(app)}
+	orphan := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            testDeploymentName + "-abc123",
+			Namespace:       testNamespace,
+			Labels:          selectorLabelsForApp(app),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(existing, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+	}
+	unrelated := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDeploymentName + "-def456",
+			Namespace: testNamespace,
+			Labels:    selectorLabelsForApp(app),
+		},
+	}
+	r, cl := createApplicationReconciler(t, app, existing, orphan, unrelated)
+	req := makeRequest()
+
+	res, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	if res.RequeueAfter != migrationRequeueDelay {
+		t.Fatalf("res.RequeueAfter got %v, wanted %v", res.RequeueAfter, migrationRequeueDelay)
+	}
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+	orphanReplicaSet(t, cl, orphan.Name)
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	d := &appsv1.Deployment{}
+	err = cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d)
+	fatalIfError(t, "failed to get replacement deployment", err)
+	wantedSelector := deploymentFromProcess(app, testProcess).Spec.Selector
+	if !reflect.DeepEqual(d.Spec.Selector, wantedSelector) {
+		t.Fatalf("got selector %#v, wanted %#v", d.Spec.Selector, wantedSelector)
+	}
+	rs := &appsv1.ReplicaSet{}
+	fatalIfError(t, "failed to get orphaned replicaset", cl.Get(context.TODO(), ns(orphan.Name, testNamespace), rs))
+
+	markDeploymentReady(t, cl, testDeploymentName)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(orphan.Name, testNamespace), &appsv1.ReplicaSet{})
+	fatalIfError(t, "failed to get unrelated replicaset", cl.Get(context.TODO(), ns(unrelated.Name, testNamespace), &appsv1.ReplicaSet{}))
+}
+
+// orphanReplicaSet removes the ReplicaSet's owner, as the garbage collector
+// does when its Deployment is deleted with the orphan propagation policy.
+func orphanReplicaSet(t *testing.T, cl client.Client, name string) {
+	t.Helper()
+	rs := &appsv1.ReplicaSet{}
+	fatalIfError(t, "failed to get replicaset", cl.Get(context.TODO(), ns(name, testNamespace), rs))
+	rs.OwnerReferences = nil
+	fatalIfError(t, "failed to update replicaset", cl.Update(context.TODO(), rs))
+}
+
+func TestRolloutStuckCondition(t *testing.T) {
+	app := makeTestApplication()
+	stuck := deploymentFromProcess(app, testProcess)
+	stuck.Status.Conditions = []appsv1.DeploymentCondition{
+		{
+			Type:   appsv1.DeploymentProgressing,
+			Status: corev1.ConditionFalse,
+			Reason: "ProgressDeadlineExceeded",
+		},
+	}
+	r, cl := createApplicationReconciler(t, app, stuck)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, api.ApplicationRolloutStuck, corev1.ConditionTrue)
+
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	d.Status.Conditions = nil
+	fatalIfError(t, "failed to update deployment", cl.Update(context.TODO(), d))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, api.ApplicationRolloutStuck, corev1.ConditionFalse)
+}
+
+func TestReadyCondition(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, api.ApplicationReady, corev1.ConditionFalse)
+
+	markDeploymentReady(t, cl, testDeploymentName)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, api.ApplicationReady, corev1.ConditionTrue)
 }
 
 func createApplicationReconciler(t *testing.T, obj ...runtime.Object) (ReconcileApplication, client.Client) {
-	scheme := createFakeScheme(t, obj...)
-	cl := fake.NewFakeClientWithScheme(scheme, obj...)
+	s := createFakeScheme(t)
+	cl := fake.NewFakeClientWithScheme(s, obj...)
 	return ReconcileApplication{
-		client: cl,
-		scheme: scheme,
+		client:   cl,
+		scheme:   s,
+		recorder: &record.FakeRecorder{},
 	}, cl
 }
 
-// TODO: Is there a nicer way of registering all types for core and apps?
-func createFakeScheme(t *testing.T, objs ...runtime.Object) *runtime.Scheme {
-	registerObjs := objs
-	registerObjs = append(registerObjs, &corev1.ConfigMap{}, &appsv1.Deployment{}, &corev1.Service{})
-	api.SchemeBuilder.Register(registerObjs...)
-	scheme, err := api.SchemeBuilder.Build()
-	if err != nil {
-		t.Fatalf("unable to build scheme: %s", err)
+func createFakeScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Kubernetes types to scheme: %s", err)
+	}
+	if err := api.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Application types to scheme: %s", err)
 	}
-	return scheme
+	return s
 }
 
 func fatalIfError(t *testing.T, msg string, err error) {
@@ -147,8 +294,13 @@ func ns(name, namespace string) types.NamespacedName {
 
 func assertConfigMapHasData(t *testing.T, name, namespace string, cl client.Client, data map[string]string) {
 	t.Helper()
+	app := &api.Application{}
+	err := cl.Get(context.TODO(), ns(name, namespace), app)
+	if err != nil {
+		t.Fatalf("failed to get application: %s", err)
+	}
 	cm := &corev1.ConfigMap{}
-	err := cl.Get(context.TODO(), ns(name+"-config", namespace), cm)
+	err = cl.Get(context.TODO(), ns(configMapNameForApp(app), namespace), cm)
 	if err != nil {
 		t.Fatalf("failed to get created config-map: %s", err)
 	}
@@ -157,6 +309,57 @@ func assertConfigMapHasData(t *testing.T, name, namespace string, cl client.Clie
 	}
 }
 
+func makeOwnerReference(app *api.Application) metav1.OwnerReference {
+	isController := true
+	return metav1.OwnerReference{
+		APIVersion: api.SchemeGroupVersion.String(),
+		Kind:       "Application",
+		Name:       app.Name,
+		UID:        app.UID,
+		Controller: &isController,
+	}
+}
+
+func markDeploymentReady(t *testing.T, cl client.Client, name string) {
+	t.Helper()
+	d := &appsv1.Deployment{}
+	err := cl.Get(context.TODO(), ns(name, testNamespace), d)
+	if err != nil {
+		t.Fatalf("failed to get deployment: %s", err)
+	}
+	d.Status.ObservedGeneration = d.Generation
+	d.Status.UpdatedReplicas = *d.Spec.Replicas
+	d.Status.AvailableReplicas = *d.Spec.Replicas
+	err = cl.Update(context.TODO(), d)
+	if err != nil {
+		t.Fatalf("failed to update deployment: %s", err)
+	}
+}
+
+func assertApplicationCondition(t *testing.T, cl client.Client, ct api.ApplicationConditionType, status corev1.ConditionStatus) {
+	t.Helper()
+	app := &api.Application{}
+	err := cl.Get(context.TODO(), ns(testAppName, testNamespace), app)
+	if err != nil {
+		t.Fatalf("failed to get application: %s", err)
+	}
+	c := findCondition(&app.Status, ct)
+	if c == nil {
+		t.Fatalf("application has no %s condition", ct)
+	}
+	if c.Status != status {
+		t.Fatalf("got %s condition %s, wanted %s", ct, c.Status, status)
+	}
+}
+
+func assertNotFound(t *testing.T, cl client.Client, name types.NamespacedName, obj runtime.Object) {
+	t.Helper()
+	err := cl.Get(context.TODO(), name, obj)
+	if !errors.IsNotFound(err) {
+		t.Fatalf("got error %v, wanted not found", err)
+	}
+}
+
 func assertDeploymentConfiguration(t *testing.T, name, namespace string, cl client.Client, r int32) {
 	t.Helper()
 	d := &appsv1.Deployment{}
@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	colourLabel        = "app.bigkevmcd.com/colour"
+	revisionAnnotation = "app.bigkevmcd.com/revision"
+
+	blue  = "blue"
+	green = "green"
+
+	defaultScaleDownDelay = time.Second * 300
+)
+
+// usesBlueGreen returns true if the process is deployed with blue/green
+// Deployments, rather than a single Deployment.
+func usesBlueGreen(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) bool {
+	return app.Spec.BlueGreen != nil && p.Name == primaryProcess(app).Name
+}
+
+// colourDeploymentFromProcess makes the Deployment for one colour of a
+// process.
+//
+// The Deployment is annotated with the revision of the pod template, so that
+// the colours can be compared to the desired revision.
+func colourDeploymentFromProcess(app *appv1alpha1.Application, process appv1alpha1.ProcessSpec, colour string) *appsv1.Deployment {
+	d := deploymentFromProcess(app, process)
+	d.Name = colourDeploymentNameForProcess(app, process, colour)
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	d.Annotations[revisionAnnotation] = revisionForProcess(app, process)
+	d.Labels[colourLabel] = colour
+	d.Spec.Selector.MatchLabels[colourLabel] = colour
+	d.Spec.Template.Labels[colourLabel] = colour
+	return d
+}
+
+// revisionForProcess returns a hash of the pod template for the process.
+func revisionForProcess(app *appv1alpha1.Application, process appv1alpha1.ProcessSpec) string {
+	template := deploymentFromProcess(app, process).Spec.Template
+	b, err := json.Marshal(template)
+	if err != nil {
+		// Marshalling a PodTemplateSpec can't fail.
+		panic(err)
+	}
+	h := fnv.New32a()
+	h.Write(b)
+	return rand.SafeEncodeString(fmt.Sprint(h.Sum32()))
+}
+
+func colourDeploymentNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec, colour string) string {
+	return deploymentNameForProcess(app, p) + "-" + colour
+}
+
+// blueGreenDeploymentNames returns the names of the Deployments for both
+// colours of the primary process, if the Application uses blue/green.
+func blueGreenDeploymentNames(app *appv1alpha1.Application) []string {
+	if app.Spec.BlueGreen == nil {
+		return nil
+	}
+	p := primaryProcess(app)
+	return []string{
+		colourDeploymentNameForProcess(app, p, blue),
+		colourDeploymentNameForProcess(app, p, green),
+	}
+}
+
+// activeDeploymentName returns the name of the Deployment for the active
+// colour, or an empty string if the Application doesn't use blue/green.
+func activeDeploymentName(app *appv1alpha1.Application) string {
+	colour := activeColour(app)
+	if colour == "" {
+		return ""
+	}
+	return colourDeploymentNameForProcess(app, primaryProcess(app), colour)
+}
+
+// activeColour returns the colour that the Service should select, or an
+// empty string if the Application doesn't use blue/green.
+func activeColour(app *appv1alpha1.Application) string {
+	if app.Spec.BlueGreen == nil || app.Status.BlueGreen == nil {
+		return ""
+	}
+	return app.Status.BlueGreen.ActiveColour
+}
+
+func otherColour(colour string) string {
+	if colour == blue {
+		return green
+	}
+	return blue
+}
+
+// reconcileBlueGreen deploys the primary process of an Application that uses
+// blue/green.
+//
+// If the desired revision differs from the active revision, it's deployed to
+// the inactive colour, and the active colour is switched once all the pods are
+// ready.  The previous colour is deleted after the scale down delay.
+//
+// It returns how long to wait before reconciling again, or zero.
+func (r *ReconcileApplication) reconcileBlueGreen(a *appv1alpha1.Application, logger logr.Logger) (time.Duration, error) {
+	if a.Spec.BlueGreen == nil {
+		a.Status.BlueGreen = nil
+		return 0, nil
+	}
+	p := primaryProcess(a)
+	p.Replicas = scaledReplicas(a, p)
+	revision := revisionForProcess(a, p)
+	if a.Status.BlueGreen == nil || a.Status.BlueGreen.ActiveColour == "" {
+		a.Status.BlueGreen = &appv1alpha1.BlueGreenStatus{ActiveColour: blue, ActiveRevision: revision}
+	}
+	status := a.Status.BlueGreen
+
+	if status.ActiveRevision == revision {
+		_, err := r.createOrUpdateDeployment(a, colourDeploymentFromProcess(a, p, status.ActiveColour), logger)
+		if err != nil {
+			return 0, err
+		}
+		return r.scaleDownInactiveColour(a, logger)
+	}
+
+	preview := colourDeploymentFromProcess(a, p, otherColour(status.ActiveColour))
+	_, err := r.createOrUpdateDeployment(a, preview, logger)
+	if err != nil {
+		return 0, err
+	}
+	found := &appsv1.Deployment{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: preview.Name, Namespace: preview.Namespace}, found)
+	if err != nil {
+		return 0, err
+	}
+	if found.Annotations[revisionAnnotation] != revision || !deploymentReady(found) {
+		logger.Info("Waiting for Deployment to become ready", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+		return migrationRequeueDelay, nil
+	}
+
+	logger.Info("Switching active colour", "Colour", otherColour(status.ActiveColour), "Revision", revision)
+	now := metav1.Now()
+	status.ActiveColour = otherColour(status.ActiveColour)
+	status.ActiveRevision = revision
+	status.SwitchedAt = &now
+	return scaleDownDelay(a), nil
+}
+
+// scaleDownInactiveColour deletes the Deployment for the inactive colour once
+// the scale down delay has passed since the Service was switched.
+func (r *ReconcileApplication) scaleDownInactiveColour(a *appv1alpha1.Application, logger logr.Logger) (time.Duration, error) {
+	status := a.Status.BlueGreen
+	if status.SwitchedAt != nil {
+		if remaining := time.Until(status.SwitchedAt.Add(scaleDownDelay(a))); remaining > 0 {
+			return remaining, nil
+		}
+	}
+
+	inactive := &appsv1.Deployment{}
+	name := colourDeploymentNameForProcess(a, primaryProcess(a), otherColour(status.ActiveColour))
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: a.Namespace}, inactive)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	logger.Info("Deleting inactive colour", "Deleted.Namespace", inactive.Namespace, "Deleted.Name", inactive.Name)
+	err = r.client.Delete(context.TODO(), inactive)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	return 0, err
+}
+
+func scaleDownDelay(app *appv1alpha1.Application) time.Duration {
+	if app.Spec.BlueGreen.ScaleDownDelaySeconds == nil {
+		return defaultScaleDownDelay
+	}
+	return time.Duration(*app.Spec.BlueGreen.ScaleDownDelaySeconds) * time.Second
+}
+
+// serviceSelectorForApp returns the selector for the Application's Service,
+// this selects the pods of the primary process, and the active colour.
+func serviceSelectorForApp(app *appv1alpha1.Application) map[string]string {
+	selector := selectorLabelsForProcess(app, primaryProcess(app))
+	if colour := activeColour(app); colour != "" {
+		selector[colourLabel] = colour
+	}
+	return selector
+}
@@ -0,0 +1,104 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestColourDeploymentFromProcess(t *testing.T) {
+	app := makeBlueGreenApplication(0)
+
+	dp := colourDeploymentFromProcess(app, testProcess, "green")
+
+	if dp.Name != testDeploymentName+"-green" {
+		t.Fatalf("Deployment got name %s, wanted %s", dp.Name, testDeploymentName+"-green")
+	}
+	if c := dp.Spec.Selector.MatchLabels["app.bigkevmcd.com/colour"]; c != "green" {
+		t.Fatalf("Deployment got colour selector %q, wanted %q", c, "green")
+	}
+	if r := dp.Annotations["app.bigkevmcd.com/revision"]; r != revisionForProcess(app, testProcess) {
+		t.Fatalf("Deployment got revision %q, wanted %q", r, revisionForProcess(app, testProcess))
+	}
+}
+
+func TestRevisionForProcess(t *testing.T) {
+	app := makeBlueGreenApplication(0)
+	updated := testProcess
+	updated.Image = "test-image:v2"
+	scaled := testProcess
+	scaled.Replicas = 10
+
+	if revisionForProcess(app, testProcess) == revisionForProcess(app, updated) {
+		t.Fatal("revisionForProcess() did not change when the image changed")
+	}
+	if revisionForProcess(app, testProcess) != revisionForProcess(app, scaled) {
+		t.Fatal("revisionForProcess() changed when the replicas changed")
+	}
+}
+
+func TestBlueGreenSwitchesColourWhenReady(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeBlueGreenApplication(0))
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName+"-blue", testNamespace, cl, testReplicas)
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+	assertServiceColour(t, cl, "blue")
+
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Processes[0].Image = "test-image:v2"
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName+"-green", testNamespace, cl, testReplicas)
+	assertServiceColour(t, cl, "blue")
+
+	markDeploymentReady(t, cl, testDeploymentName+"-green")
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertServiceColour(t, cl, "green")
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	if c := app.Status.BlueGreen.ActiveColour; c != "green" {
+		t.Fatalf("got active colour %q, wanted %q", c, "green")
+	}
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testDeploymentName+"-blue", testNamespace), &appsv1.Deployment{})
+}
+
+func makeBlueGreenApplication(delay int32) *appv1alpha1.Application {
+	app := makeTestApplication()
+	app.Spec.BlueGreen = &appv1alpha1.BlueGreenSpec{ScaleDownDelaySeconds: &delay}
+	return app
+}
+
+func assertServiceColour(t *testing.T, cl client.Client, colour string) {
+	t.Helper()
+	svc := &corev1.Service{}
+	err := cl.Get(context.TODO(), ns(testAppName, testNamespace), svc)
+	if err != nil {
+		t.Fatalf("failed to get service: %s", err)
+	}
+	wanted := map[string]string{
+		"app.kubernetes.io/name":      testAppName,
+		"app.kubernetes.io/component": "web",
+		"app.bigkevmcd.com/colour":    colour,
+	}
+	if !reflect.DeepEqual(svc.Spec.Selector, wanted) {
+		t.Fatalf("Service got selector %#v, wanted %#v", svc.Spec.Selector, wanted)
+	}
+}
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	trackLabel  = "app.bigkevmcd.com/track"
+	stableTrack = "stable"
+	canaryTrack = "canary"
+)
+
+// canaryDeploymentFromProcess makes the Deployment for the canary of a
+// process.
+//
+// The canary pods have the same selector labels as the process pods, so that
+// they receive traffic from the same Service, and are on the canary track,
+// rather than the stable track, so that the Deployments' selectors don't
+// overlap.
+func canaryDeploymentFromProcess(app *appv1alpha1.Application, process appv1alpha1.ProcessSpec) *appsv1.Deployment {
+	canary := process
+	canary.Image = process.Canary.Image
+	canary.Replicas = canaryReplicas(process)
+	d := deploymentFromProcess(app, canary)
+	d.Name = canaryDeploymentNameForProcess(app, process)
+	d.Labels[trackLabel] = canaryTrack
+	d.Spec.Selector.MatchLabels[trackLabel] = canaryTrack
+	d.Spec.Template.Labels[trackLabel] = canaryTrack
+	return d
+}
+
+// validateCanaries checks that each canary has exactly one of replicas or
+// weight, and that the value is in range.
+func validateCanaries(a *appv1alpha1.Application) error {
+	for _, p := range a.Spec.Processes {
+		c := p.Canary
+		if c == nil {
+			continue
+		}
+		if (c.Replicas == nil) == (c.Weight == 0) {
+			return fmt.Errorf("process %s: canary must have exactly one of replicas or weight", p.Name)
+		}
+		if c.Replicas != nil && *c.Replicas < 1 {
+			return fmt.Errorf("process %s: canary replicas must be at least 1, got %d", p.Name, *c.Replicas)
+		}
+		if c.Replicas == nil && (c.Weight < 1 || c.Weight > 100) {
+			return fmt.Errorf("process %s: canary weight must be between 1 and 100, got %d", p.Name, c.Weight)
+		}
+	}
+	return nil
+}
+
+// canaryReplicas returns the number of pods to run for the canary of a
+// process.
+//
+// If the canary is configured with a weight, this is the percentage of the
+// process's replicas, rounded up.  There are no canary pods if the process is
+// scaled to zero.
+func canaryReplicas(process appv1alpha1.ProcessSpec) int32 {
+	if process.Replicas == 0 {
+		return 0
+	}
+	if process.Canary.Replicas != nil {
+		return *process.Canary.Replicas
+	}
+	replicas := (process.Replicas*process.Canary.Weight + 99) / 100
+	if replicas < 1 {
+		return 1
+	}
+	return replicas
+}
+
+// primaryReplicas returns the number of pods to run with the process's own
+// image.
+//
+// When the canary is configured with a weight, the canary pods replace some
+// of the process's pods.
+func primaryReplicas(process appv1alpha1.ProcessSpec) int32 {
+	if process.Canary == nil || process.Canary.Replicas != nil {
+		return process.Replicas
+	}
+	replicas := process.Replicas - canaryReplicas(process)
+	if replicas < 0 {
+		return 0
+	}
+	return replicas
+}
+
+func canaryDeploymentNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return deploymentNameForProcess(app, p) + "-" + canaryTrack
+}
+
+// applyCanaryActions folds canaries with an action back into their
+// processes, and updates the Application.
+//
+// Promoting a canary replaces the image of the process with the canary image,
+// aborting leaves the process unchanged, and in both cases the canary is
+// removed, and its Deployment will be retired.
+func (r *ReconcileApplication) applyCanaryActions(a *appv1alpha1.Application, logger logr.Logger) error {
+	changed := false
+	for i := range a.Spec.Processes {
+		p := &a.Spec.Processes[i]
+		if p.Canary == nil {
+			continue
+		}
+		switch p.Canary.Action {
+		case appv1alpha1.CanaryPromote:
+			logger.Info("Promoting canary", "Process", p.Name, "Image", p.Canary.Image)
+			p.Image = p.Canary.Image
+		case appv1alpha1.CanaryAbort:
+			logger.Info("Aborting canary", "Process", p.Name, "Image", p.Canary.Image)
+		default:
+			continue
+		}
+		p.Canary = nil
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.client.Update(context.TODO(), a)
+}
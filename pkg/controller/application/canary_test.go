@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const testCanaryImage = "test-image:canary"
+
+func TestCanaryDeploymentFromProcess(t *testing.T) {
+	app := makeTestApplication()
+	process := testProcess
+	process.Canary = &appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 20}
+
+	dp := canaryDeploymentFromProcess(app, process)
+
+	if dp.Name != testAppName+"-web-canary" {
+		t.Fatalf("Deployment got name %s, wanted %s", dp.Name, testAppName+"-web-canary")
+	}
+	if *dp.Spec.Replicas != 1 {
+		t.Fatalf("Deployment got %d Replicas, wanted 1", *dp.Spec.Replicas)
+	}
+	wantedSelector := map[string]string{
+		"app.kubernetes.io/name":      testAppName,
+		"app.kubernetes.io/component": "web",
+		"app.bigkevmcd.com/track":     "canary",
+	}
+	if !reflect.DeepEqual(dp.Spec.Selector.MatchLabels, wantedSelector) {
+		t.Fatalf("Deployment got %#v MatchLabels, wanted %#v", dp.Spec.Selector.MatchLabels, wantedSelector)
+	}
+	if v := dp.Spec.Template.Labels["app.kubernetes.io/version"]; v != "canary" {
+		t.Fatalf("Deployment got version label %q, wanted %q", v, "canary")
+	}
+	if image := dp.Spec.Template.Spec.Containers[0].Image; image != testCanaryImage {
+		t.Fatalf("Deployment got image %s, wanted %s", image, testCanaryImage)
+	}
+}
+
+func TestPrimaryDeploymentDoesNotSelectCanaryPods(t *testing.T) {
+	app := makeTestApplication()
+	process := testProcess
+	process.Canary = &appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 20}
+
+	primary := deploymentFromProcess(app, process)
+	canary := canaryDeploymentFromProcess(app, process)
+
+	if selectorMatches(primary.Spec.Selector.MatchLabels, canary.Spec.Template.Labels) {
+		t.Fatalf("primary Deployment selector %#v matches canary pods %#v", primary.Spec.Selector.MatchLabels, canary.Spec.Template.Labels)
+	}
+	if selectorMatches(canary.Spec.Selector.MatchLabels, primary.Spec.Template.Labels) {
+		t.Fatalf("canary Deployment selector %#v matches primary pods %#v", canary.Spec.Selector.MatchLabels, primary.Spec.Template.Labels)
+	}
+	service := serviceFromApplication(app)
+	for _, d := range []*appsv1.Deployment{primary, canary} {
+		if !selectorMatches(service.Spec.Selector, d.Spec.Template.Labels) {
+			t.Fatalf("Service selector %#v doesn't match %s pods %#v", service.Spec.Selector, d.Name, d.Spec.Template.Labels)
+		}
+	}
+}
+
+func selectorMatches(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCanaryReplicas(t *testing.T) {
+	two := int32(2)
+	replicaTests := []struct {
+		replicas      int32
+		canary        *appv1alpha1.CanarySpec
+		wantedCanary  int32
+		wantedPrimary int32
+	}{
+		{5, &appv1alpha1.CanarySpec{Weight: 20}, 1, 4},
+		{5, &appv1alpha1.CanarySpec{Weight: 50}, 3, 2},
+		{1, &appv1alpha1.CanarySpec{Weight: 10}, 1, 0},
+		{5, &appv1alpha1.CanarySpec{Replicas: &two}, 2, 5},
+		{0, &appv1alpha1.CanarySpec{Weight: 20}, 0, 0},
+		{0, &appv1alpha1.CanarySpec{Replicas: &two}, 0, 0},
+	}
+
+	for _, tt := range replicaTests {
+		p := appv1alpha1.ProcessSpec{Replicas: tt.replicas, Canary: tt.canary}
+		if r := canaryReplicas(p); r != tt.wantedCanary {
+			t.Errorf("canaryReplicas(%#v) got %d, wanted %d", p, r, tt.wantedCanary)
+		}
+		if r := primaryReplicas(p); r != tt.wantedPrimary {
+			t.Errorf("primaryReplicas(%#v) got %d, wanted %d", p, r, tt.wantedPrimary)
+		}
+	}
+}
+
+func TestValidateCanaries(t *testing.T) {
+	zero, two := int32(0), int32(2)
+	validateTests := []struct {
+		canary  *appv1alpha1.CanarySpec
+		wantErr string
+	}{
+		{nil, ""},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 20}, ""},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 100}, ""},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Replicas: &two}, ""},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage}, "process web: canary must have exactly one of replicas or weight"},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Replicas: &two, Weight: 20}, "process web: canary must have exactly one of replicas or weight"},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Replicas: &zero}, "process web: canary replicas must be at least 1, got 0"},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: -10}, "process web: canary weight must be between 1 and 100, got -10"},
+		{&appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 101}, "process web: canary weight must be between 1 and 100, got 101"},
+	}
+
+	for _, tt := range validateTests {
+		app := makeTestApplication()
+		app.Spec.Processes[0].Canary = tt.canary
+		err := validateCanaries(app)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validateCanaries(%#v) failed: %s", tt.canary, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("validateCanaries(%#v) got %v, wanted %q", tt.canary, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPromoteCanary(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].Canary = &appv1alpha1.CanarySpec{Image: testCanaryImage, Weight: 20}
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 4)
+	assertDeploymentConfiguration(t, testDeploymentName+"-canary", testNamespace, cl, 1)
+
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	updated.Spec.Processes[0].Canary.Action = appv1alpha1.CanaryPromote
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), updated))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	if p := updated.Spec.Processes[0]; p.Image != testCanaryImage || p.Canary != nil {
+		t.Fatalf("got process %#v, wanted canary promoted", p)
+	}
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	if image := d.Spec.Template.Spec.Containers[0].Image; image != testCanaryImage {
+		t.Fatalf("Deployment got image %s, wanted %s", image, testCanaryImage)
+	}
+}
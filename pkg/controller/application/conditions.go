@@ -0,0 +1,52 @@
+package application
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// findCondition returns the condition with the provided type from the status
+// or nil if there is no condition with that type.
+func findCondition(status *appv1alpha1.ApplicationStatus, t appv1alpha1.ApplicationConditionType) *appv1alpha1.ApplicationCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == t {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition records the state of a condition in the status.
+func setCondition(status *appv1alpha1.ApplicationStatus, t appv1alpha1.ApplicationConditionType, s corev1.ConditionStatus, reason, message string) {
+	SetCondition(&status.Conditions, t, s, reason, message)
+}
+
+// SetCondition records the state of a condition in a list of conditions, this
+// is shared with the other controllers that report ApplicationConditions.
+//
+// The LastTransitionTime is only changed when the status of the condition
+// changes.
+func SetCondition(conditions *[]appv1alpha1.ApplicationCondition, t appv1alpha1.ApplicationConditionType, s corev1.ConditionStatus, reason, message string) {
+	for i := range *conditions {
+		c := &(*conditions)[i]
+		if c.Type != t {
+			continue
+		}
+		if c.Status != s {
+			c.Status = s
+			c.LastTransitionTime = metav1.Now()
+		}
+		c.Reason = reason
+		c.Message = message
+		return
+	}
+	*conditions = append(*conditions, appv1alpha1.ApplicationCondition{
+		Type:               t,
+		Status:             s,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
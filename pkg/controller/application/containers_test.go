@@ -0,0 +1,66 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMakePodSpecWithSidecars(t *testing.T) {
+	app := makeTestApplication()
+	process := testProcess
+	process.Sidecars = []appv1alpha1.ContainerSpec{
+		{Container: corev1.Container{Name: "proxy", Image: "proxy:v1", Env: []corev1.EnvVar{{Name: "PORT", Value: "5432"}}}},
+	}
+
+	s := makePodSpec(app, process)
+
+	if l := len(s.Containers); l != 2 {
+		t.Fatalf("makePodSpec() got %d containers, wanted 2", l)
+	}
+	if n := s.Containers[0].Name; n != testAppName+"-web" {
+		t.Fatalf("makePodSpec() got main container name %s, wanted %s", n, testAppName+"-web")
+	}
+	sidecar := s.Containers[1]
+	wantedEnv := append(makeEnvFromApp(app), corev1.EnvVar{Name: "PORT", Value: "5432"})
+	if !reflect.DeepEqual(sidecar.Env, wantedEnv) {
+		t.Fatalf("sidecar got env %#v, wanted %#v", sidecar.Env, wantedEnv)
+	}
+	if !reflect.DeepEqual(sidecar.SecurityContext, defaultContainerSecurityContext()) {
+		t.Fatalf("sidecar got security context %#v, wanted %#v", sidecar.SecurityContext, defaultContainerSecurityContext())
+	}
+}
+
+func TestMakePodSpecWithInitContainers(t *testing.T) {
+	app := makeTestApplication()
+	inherit := false
+	process := testProcess
+	process.InitContainers = []appv1alpha1.ContainerSpec{
+		{Container: corev1.Container{Name: "setup", Image: "setup:v1"}, InheritEnvironment: &inherit},
+	}
+
+	s := makePodSpec(app, process)
+
+	if l := len(s.InitContainers); l != 1 {
+		t.Fatalf("makePodSpec() got %d init containers, wanted 1", l)
+	}
+	if env := s.InitContainers[0].Env; env != nil {
+		t.Fatalf("init container got env %#v, wanted nil", env)
+	}
+}
+
+func TestValidateSecurityWithPrivilegedSidecar(t *testing.T) {
+	app := makeTestApplication()
+	privileged := true
+	app.Spec.Processes[0].Sidecars = []appv1alpha1.ContainerSpec{
+		{Container: corev1.Container{Name: "debug", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}}},
+	}
+	r := &ReconcileApplication{}
+
+	if err := r.validateSecurity(app); err == nil {
+		t.Fatal("validateSecurity() got nil, wanted an error")
+	}
+}
@@ -1,50 +1,121 @@
 package application
 
 import (
+	"sort"
+	"strings"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
 )
 
-// configMapFromApplication makes a ConfigMap based on the Application.
+// These are the recommended labels from
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+const (
+	nameLabel      = "app.kubernetes.io/name"
+	instanceLabel  = "app.kubernetes.io/instance"
+	componentLabel = "app.kubernetes.io/component"
+	partOfLabel    = "app.kubernetes.io/part-of"
+	managedByLabel = "app.kubernetes.io/managed-by"
+	versionLabel   = "app.kubernetes.io/version"
+
+	managedBy = "applications"
+
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// TemplateSpecAnnotation is the spec that an ApplicationTemplate last
+	// applied to the Application.
+	TemplateSpecAnnotation = "app.bigkevmcd.com/template-spec"
+
+	zoneLabel = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// configMapFromApplication makes a ConfigMap based on the Application, with
+// the environment and the content of the files.
 func configMapFromApplication(app *appv1alpha1.Application) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: makeObjectMeta(configMapNameForApp(app), app),
-		Data:       app.Spec.Environment,
+		Data:       configDataForApp(app),
+	}
+}
+
+// configDataForApp returns the data for the Application's ConfigMap.
+func configDataForApp(app *appv1alpha1.Application) map[string]string {
+	data := environmentForApp(app)
+	for path, content := range app.Spec.Files {
+		data[fileKey(path)] = content
 	}
+	return data
 }
 
-// deploymentFromApplication makes a deployment based on the Application.
-// TODO: fix this for multiple processes.
-func deploymentFromApplication(app *appv1alpha1.Application) *appsv1.Deployment {
-	process := app.Spec.Processes[0]
-	return &appsv1.Deployment{
-		ObjectMeta: makeObjectMeta(app.Name, app),
+// deploymentsFromApplication makes a Deployment for each of the processes in
+// the Application, and for their canaries.
+//
+// Processes that are deployed with blue/green are managed by
+// reconcileBlueGreen instead, and scheduled processes by reconcileCronJobs.
+func deploymentsFromApplication(app *appv1alpha1.Application) []*appsv1.Deployment {
+	deployments := []*appsv1.Deployment{}
+	for _, p := range app.Spec.Processes {
+		if usesBlueGreen(app, p) || isScheduled(p) {
+			continue
+		}
+		p.Replicas = scaledReplicas(app, p)
+		primary := p
+		primary.Replicas = primaryReplicas(p)
+		deployments = append(deployments, deploymentFromProcess(app, primary))
+		if p.Canary != nil {
+			deployments = append(deployments, canaryDeploymentFromProcess(app, p))
+		}
+	}
+	return deployments
+}
+
+// deploymentFromProcess makes a Deployment for a process in the Application.
+//
+// The pods are on the stable track, so that the Deployment doesn't select
+// the pods of the process's canary.
+func deploymentFromProcess(app *appv1alpha1.Application, process appv1alpha1.ProcessSpec) *appsv1.Deployment {
+	replicas := replicasForProcess(app, process)
+	d := &appsv1.Deployment{
+		ObjectMeta: makeProcessObjectMeta(deploymentNameForProcess(app, process), app, process),
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &process.Replicas,
-			Selector: makeLabelSelector(app),
+			Replicas:                &replicas,
+			Selector:                makeLabelSelector(app, process),
+			Strategy:                process.Strategy,
+			MinReadySeconds:         process.MinReadySeconds,
+			ProgressDeadlineSeconds: process.ProgressDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: makeObjectMeta(app.Name, app),
+				ObjectMeta: makePodTemplateObjectMeta(app, process),
 				Spec:       makePodSpec(app, process),
 			},
 		},
 	}
+	d.Labels[trackLabel] = stableTrack
+	d.Spec.Selector.MatchLabels[trackLabel] = stableTrack
+	d.Spec.Template.Labels[trackLabel] = stableTrack
+	return d
 }
 
 // serviceFromApplication makes a service based on the Application.
+//
+// The service selects the pods for the primary process, and if the
+// Application uses blue/green, the active colour.
 // TODO: What to do about configuring the service type, port and protocol?
 func serviceFromApplication(app *appv1alpha1.Application) *corev1.Service {
 	return &corev1.Service{
-		ObjectMeta: makeObjectMeta(app.Name, app),
+		ObjectMeta: makeObjectMeta(serviceNameForApp(app), app),
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeNodePort,
-			Selector: labelsForApp(app),
+			Selector: serviceSelectorForApp(app),
 			Ports: []corev1.ServicePort{
 				{
-					Protocol: corev1.ProtocolTCP,
-					Port:     80,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       servicePortForApp(app),
+					TargetPort: intstr.FromInt(int(serviceTargetPort(app, primaryProcess(app)))),
 				},
 			},
 		},
@@ -53,43 +124,217 @@ func serviceFromApplication(app *appv1alpha1.Application) *corev1.Service {
 
 func makeObjectMeta(name string, app *appv1alpha1.Application) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
-		Name:      name,
-		Namespace: app.Namespace,
-		Labels:    labelsForApp(app),
+		Name:        name,
+		Namespace:   app.Namespace,
+		Labels:      labelsForApp(app),
+		Annotations: annotationsForApp(app),
 	}
 }
 
-func makeLabelSelector(app *appv1alpha1.Application) *metav1.LabelSelector {
+// makeProcessObjectMeta is like makeObjectMeta, but the labels also identify
+// the process and the version of the image it runs.
+func makeProcessObjectMeta(name string, app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) metav1.ObjectMeta {
+	meta := makeObjectMeta(name, app)
+	meta.Labels = labelsForProcess(app, p)
+	return meta
+}
+
+// makePodTemplateObjectMeta returns the metadata for the process's pods.
+func makePodTemplateObjectMeta(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) metav1.ObjectMeta {
+	meta := makeProcessObjectMeta("", app, p)
+	meta.Annotations = addSeccompProfile(meta.Annotations)
+	meta.Annotations[configHashAnnotation] = configHash(app)
+	return meta
+}
+
+// makeLabelSelector returns the selector for the pods belonging to a process
+// in the Application.
+//
+// Deployment selectors are immutable, changing this will cause existing
+// Deployments to be migrated.
+func makeLabelSelector(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) *metav1.LabelSelector {
 	return &metav1.LabelSelector{
-		MatchLabels: labelsForApp(app),
+		MatchLabels: selectorLabelsForProcess(app, p),
 	}
 }
 
+// selectorLabelsForApp returns the subset of labels that is used to select
+// all the objects for an Application.
+func selectorLabelsForApp(app *appv1alpha1.Application) map[string]string {
+	return map[string]string{nameLabel: app.ObjectMeta.Name}
+}
+
+// selectorLabelsForProcess returns the subset of labels that is used to
+// select the pods for a process in an Application.
+func selectorLabelsForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) map[string]string {
+	labels := selectorLabelsForApp(app)
+	labels[componentLabel] = p.Name
+	return labels
+}
+
+// labelsForApp returns the labels for the objects generated from the
+// Application, these are the labels from the Application, with the
+// recommended labels added.
 func labelsForApp(app *appv1alpha1.Application) map[string]string {
-	return map[string]string{"app.kubernetes.io/name": app.ObjectMeta.Name}
+	labels := map[string]string{}
+	for k, v := range app.ObjectMeta.Labels {
+		labels[k] = v
+	}
+	if labels[partOfLabel] == "" {
+		labels[partOfLabel] = app.ObjectMeta.Name
+	}
+	labels[instanceLabel] = app.ObjectMeta.Name
+	labels[managedByLabel] = managedBy
+	for k, v := range selectorLabelsForApp(app) {
+		labels[k] = v
+	}
+	return labels
+}
+
+// labelsForProcess returns the labels for the objects generated for a
+// specific process in the Application.
+func labelsForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) map[string]string {
+	labels := labelsForApp(app)
+	labels[componentLabel] = p.Name
+	if v := imageVersion(p.Image); v != "" {
+		labels[versionLabel] = v
+	}
+	return labels
+}
+
+// annotationsForApp returns the annotations from the Application that should
+// be copied to the generated objects.
+func annotationsForApp(app *appv1alpha1.Application) map[string]string {
+	if len(app.ObjectMeta.Annotations) == 0 {
+		return nil
+	}
+	annotations := map[string]string{}
+	for k, v := range app.ObjectMeta.Annotations {
+		if k == lastAppliedAnnotation || k == TemplateSpecAnnotation || k == mirroredReplicasAnnotation {
+			continue
+		}
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// imageVersion returns the tag from an image reference, or an empty string if
+// there is no tag, or it's not usable as a label value.
+func imageVersion(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := image[i+1:]
+	if len(validation.IsValidLabelValue(tag)) != 0 {
+		return ""
+	}
+	return tag
 }
 
+// makePodSpec returns the spec for the process's pods.
+//
+// The process's container is always the first container, and is named
+// "<application>-<process>", followed by the process's sidecars.
 func makePodSpec(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) corev1.PodSpec {
-	return corev1.PodSpec{
-		Containers: []corev1.Container{
-			{
-				Name:  app.ObjectMeta.Name + "-" + p.Name,
-				Image: p.Image,
-				Env:   makeEnvFromApp(app),
-			},
+	mounts := append(makeFilesVolumeMounts(app), makeAddonVolumeMounts(app)...)
+	containers := []corev1.Container{
+		{
+			Name:            containerNameForProcess(app, p),
+			Image:           p.Image,
+			Env:             makeEnvFromApp(app),
+			SecurityContext: makeContainerSecurityContext(p),
+			VolumeMounts:    append(mounts, p.VolumeMounts...),
 		},
 	}
+	return corev1.PodSpec{
+		NodeSelector:       p.NodeSelector,
+		Tolerations:        p.Tolerations,
+		Affinity:           makeAffinity(app, p),
+		SecurityContext:    makePodSecurityContext(p),
+		ServiceAccountName: serviceAccountNameForApp(app),
+		ImagePullSecrets:   imagePullSecretsForApp(app),
+		Volumes:            makeVolumes(app, p),
+		InitContainers:     makeContainers(app, p.InitContainers),
+		Containers:         append(containers, makeContainers(app, p.Sidecars)...),
+	}
+}
+
+// makeContainers returns the additional containers for a process.
+//
+// Unless they opt out, the Application's environment is added before the
+// container's own environment, so that the container can override it.
+// Containers without a security context get the hardened default.
+func makeContainers(app *appv1alpha1.Application, specs []appv1alpha1.ContainerSpec) []corev1.Container {
+	var containers []corev1.Container
+	for _, spec := range specs {
+		c := *spec.Container.DeepCopy()
+		if spec.InheritEnvironment == nil || *spec.InheritEnvironment {
+			c.Env = append(makeEnvFromApp(app), c.Env...)
+		}
+		if c.SecurityContext == nil {
+			c.SecurityContext = defaultContainerSecurityContext()
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// additionalContainers returns the process's init containers and sidecars.
+func additionalContainers(p appv1alpha1.ProcessSpec) []appv1alpha1.ContainerSpec {
+	containers := []appv1alpha1.ContainerSpec{}
+	containers = append(containers, p.InitContainers...)
+	return append(containers, p.Sidecars...)
+}
+
+// makeAffinity returns the affinity for the process's pods.
+//
+// If the process should be spread across zones, a preference for pods not to
+// be scheduled in the same zone as the process's other pods is added.
+func makeAffinity(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) *corev1.Affinity {
+	if !p.SpreadAcrossZones {
+		return p.Affinity
+	}
+	affinity := p.Affinity.DeepCopy()
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: makeLabelSelector(app, p),
+				TopologyKey:   zoneLabel,
+			},
+		})
+	return affinity
 }
 
+// makeEnvFromApp returns the environment variables for the Application,
+// followed by the variables for its addons.
+//
+// These are sorted by name so that the pod template doesn't change between
+// reconciliations.
 func makeEnvFromApp(app *appv1alpha1.Application) []corev1.EnvVar {
 	vars := []corev1.EnvVar{}
-	for k, _ := range app.Spec.Environment {
+	configMapName := configMapNameForApp(app)
+	for _, k := range sortedKeys(environmentForApp(app)) {
 		envVar := corev1.EnvVar{
 			Name: k,
 			ValueFrom: &corev1.EnvVarSource{
 				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: configMapNameForApp(app),
+						Name: configMapName,
 					},
 					Key: k,
 				},
@@ -97,9 +342,74 @@ func makeEnvFromApp(app *appv1alpha1.Application) []corev1.EnvVar {
 		}
 		vars = append(vars, envVar)
 	}
-	return vars
+	return append(vars, makeAddonEnv(app)...)
 }
 
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resourceName returns the name of a resource generated for the Application,
+// the parts are joined to the Application's NamePrefix, or its name if that
+// isn't set.
+//
+// All the names of generated resources must come from here, so that they
+// honour the NamePrefix.
+func resourceName(app *appv1alpha1.Application, parts ...string) string {
+	prefix := app.Spec.NamePrefix
+	if prefix == "" {
+		prefix = app.Name
+	}
+	return strings.Join(append([]string{prefix}, parts...), "-")
+}
+
+// configMapNameForApp returns the name of the ConfigMap for the current
+// configuration of the Application.
+//
+// The name includes a hash of the data, so that a changed configuration is
+// written to a new ConfigMap, and only used by the release Job and the
+// Deployments once they are updated, the pods that are being replaced keep
+// the configuration that they were started with.
 func configMapNameForApp(app *appv1alpha1.Application) string {
-	return app.Name + "-config"
+	return resourceName(app, "config", configHash(app))
+}
+
+func deploymentNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return resourceName(app, p.Name)
+}
+
+// containerNameForProcess returns the name of the process's container, this
+// doesn't use the NamePrefix, so that tooling that looks for the container
+// by the Application's name keeps working.
+func containerNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return app.Name + "-" + p.Name
+}
+
+func serviceNameForApp(app *appv1alpha1.Application) string {
+	return resourceName(app)
+}
+
+func servicePortForApp(app *appv1alpha1.Application) int32 {
+	return 80
+}
+
+// serviceTargetPort returns the port on the process's pods that the
+// Application's Service sends traffic to, this is the process's port, or the
+// same port as the Service if the process doesn't have one.
+func serviceTargetPort(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) int32 {
+	if p.Port != 0 {
+		return p.Port
+	}
+	return servicePortForApp(app)
+}
+
+// primaryProcess returns the process that receives traffic from the
+// Application's Service, this is the first process.
+func primaryProcess(app *appv1alpha1.Application) appv1alpha1.ProcessSpec {
+	return app.Spec.Processes[0]
 }
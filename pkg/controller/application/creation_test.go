@@ -4,14 +4,33 @@ import (
 	"reflect"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
 )
 
 var (
-	testLabels      = map[string]string{"app.kubernetes.io/name": testAppName}
+	testSelector = map[string]string{
+		"app.kubernetes.io/name":      testAppName,
+		"app.kubernetes.io/component": "web",
+	}
+	testLabels = map[string]string{
+		"app.kubernetes.io/name":       testAppName,
+		"app.kubernetes.io/instance":   testAppName,
+		"app.kubernetes.io/part-of":    testAppName,
+		"app.kubernetes.io/managed-by": "applications",
+	}
+	testProcessLabels = map[string]string{
+		"app.kubernetes.io/name":       testAppName,
+		"app.kubernetes.io/instance":   testAppName,
+		"app.kubernetes.io/part-of":    testAppName,
+		"app.kubernetes.io/managed-by": "applications",
+		"app.kubernetes.io/component":  "web",
+		"app.kubernetes.io/version":    "latest",
+	}
 	testEnvironment = map[string]string{"TEST_MODE": "true"}
 	testImage       = "test-image:latest"
 	testProcess     = appv1alpha1.ProcessSpec{
@@ -35,6 +54,17 @@ func TestConfigMapFromApplication(t *testing.T) {
 	}
 }
 
+func TestConfigMapNameChangesWithConfiguration(t *testing.T) {
+	app := makeTestApplication()
+	name := configMapNameForApp(app)
+
+	app.Spec.Environment = map[string]string{"TEST_MODE": "false"}
+
+	if n := configMapNameForApp(app); n == name {
+		t.Fatalf("configMapNameForApp() got %s after the environment changed", n)
+	}
+}
+
 func TestMakeEnvFromApp(t *testing.T) {
 	testMode := "TEST_MODE"
 	app := makeTestApplication()
@@ -69,11 +99,13 @@ func TestMakePodSpec(t *testing.T) {
 	s := makePodSpec(app, testProcess)
 
 	wanted := corev1.PodSpec{
+		SecurityContext: makePodSecurityContext(testProcess),
 		Containers: []corev1.Container{
 			{
-				Name:  app.ObjectMeta.Name + "-" + "web",
-				Image: testImage,
-				Env:   makeEnvFromApp(app),
+				Name:            app.ObjectMeta.Name + "-" + "web",
+				Image:           testImage,
+				Env:             makeEnvFromApp(app),
+				SecurityContext: makeContainerSecurityContext(testProcess),
 			},
 		},
 	}
@@ -82,7 +114,66 @@ func TestMakePodSpec(t *testing.T) {
 	}
 }
 
-func TestDeploymentFromApplication(t *testing.T) {
+func TestMakePodSpecWithScheduling(t *testing.T) {
+	app := makeTestApplication()
+	process := testProcess
+	process.NodeSelector = map[string]string{"disktype": "ssd"}
+	process.Tolerations = []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "web", Effect: corev1.TaintEffectNoSchedule},
+	}
+
+	s := makePodSpec(app, process)
+
+	if !reflect.DeepEqual(s.NodeSelector, process.NodeSelector) {
+		t.Fatalf("makePodSpec() got NodeSelector %#v, wanted %#v", s.NodeSelector, process.NodeSelector)
+	}
+	if !reflect.DeepEqual(s.Tolerations, process.Tolerations) {
+		t.Fatalf("makePodSpec() got Tolerations %#v, wanted %#v", s.Tolerations, process.Tolerations)
+	}
+}
+
+func TestMakeAffinityWithSpreadAcrossZones(t *testing.T) {
+	app := makeTestApplication()
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "disktype", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}},
+					},
+				},
+			},
+		},
+	}
+	process := testProcess
+	process.Affinity = &corev1.Affinity{NodeAffinity: nodeAffinity}
+	process.SpreadAcrossZones = true
+
+	affinity := makeAffinity(app, process)
+
+	wanted := &corev1.Affinity{
+		NodeAffinity: nodeAffinity,
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: testSelector},
+						TopologyKey:   "failure-domain.beta.kubernetes.io/zone",
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(affinity, wanted) {
+		t.Fatalf("makeAffinity() got %#v, wanted %#v", affinity, wanted)
+	}
+	if process.Affinity.PodAntiAffinity != nil {
+		t.Fatal("makeAffinity() modified the process affinity")
+	}
+}
+
+func TestDeploymentFromProcess(t *testing.T) {
 	process := appv1alpha1.ProcessSpec{
 		Name:     "web",
 		Replicas: 5,
@@ -92,33 +183,104 @@ func TestDeploymentFromApplication(t *testing.T) {
 	app := makeTestApplication()
 	app.Spec.Processes = []appv1alpha1.ProcessSpec{process}
 
-	dp := deploymentFromApplication(app)
+	dp := deploymentFromProcess(app, process)
 
+	if dp.Name != testAppName+"-web" {
+		t.Fatalf("Deployment got name %s, wanted %s", dp.Name, testAppName+"-web")
+	}
 	if *dp.Spec.Replicas != 5 {
 		t.Fatalf("Deployment got %d Replicas, wanted 5", *dp.Spec.Replicas)
 	}
-	if !reflect.DeepEqual(dp.Spec.Selector.MatchLabels, testLabels) {
-		t.Fatalf("Deployment got %#v MatchLabels, wanted %#v", dp.Spec.Selector.MatchLabels, testLabels)
+	wantedSelector := withTrack(testSelector, "stable")
+	if !reflect.DeepEqual(dp.Spec.Selector.MatchLabels, wantedSelector) {
+		t.Fatalf("Deployment got %#v MatchLabels, wanted %#v", dp.Spec.Selector.MatchLabels, wantedSelector)
 	}
-	if !reflect.DeepEqual(dp.Labels, testLabels) {
-		t.Fatalf("Deployment got labels %#v, wanted %#v", dp.Labels, testLabels)
+	wantedLabels := withTrack(testProcessLabels, "stable")
+	if !reflect.DeepEqual(dp.Labels, wantedLabels) {
+		t.Fatalf("Deployment got labels %#v, wanted %#v", dp.Labels, wantedLabels)
 	}
 	if l := len(dp.Spec.Template.Spec.Containers); l != 1 {
 		t.Fatalf("Deployment got %d containers, wanted 1", l)
 	}
 
 	wantedContainer := corev1.Container{
-		Name:  app.ObjectMeta.Name + "-web",
-		Image: testImage,
-		Env:   makeEnvFromApp(app),
+		Name:            app.ObjectMeta.Name + "-web",
+		Image:           testImage,
+		Env:             makeEnvFromApp(app),
+		SecurityContext: makeContainerSecurityContext(process),
 	}
 	if !reflect.DeepEqual(dp.Spec.Template.Spec.Containers[0], wantedContainer) {
 		t.Fatalf("Deployment got containers %#v, wanted %#v", dp.Spec.Template.Spec.Containers[0], wantedContainer)
 	}
-	if !reflect.DeepEqual(dp.Spec.Template.ObjectMeta.Labels, testLabels) {
-		t.Fatalf("Deployment got deployment labels %#v, wanted %#v", dp.Spec.Template.ObjectMeta.Labels, testLabels)
+	if !reflect.DeepEqual(dp.Spec.Template.ObjectMeta.Labels, wantedLabels) {
+		t.Fatalf("Deployment got deployment labels %#v, wanted %#v", dp.Spec.Template.ObjectMeta.Labels, wantedLabels)
+	}
+
+}
+
+// withTrack returns a copy of the labels with the track label added.
+func withTrack(labels map[string]string, track string) map[string]string {
+	l := map[string]string{"app.bigkevmcd.com/track": track}
+	for k, v := range labels {
+		l[k] = v
+	}
+	return l
+}
+
+func TestDeploymentFromProcessWithStrategy(t *testing.T) {
+	maxSurge := intstr.FromString("50%")
+	maxUnavailable := intstr.FromInt(0)
+	deadline := int32(300)
+	process := testProcess
+	process.Strategy = appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+	process.MinReadySeconds = 10
+	process.ProgressDeadlineSeconds = &deadline
+	app := makeTestApplication()
+
+	dp := deploymentFromProcess(app, process)
+
+	if !reflect.DeepEqual(dp.Spec.Strategy, process.Strategy) {
+		t.Fatalf("Deployment got strategy %#v, wanted %#v", dp.Spec.Strategy, process.Strategy)
+	}
+	if dp.Spec.MinReadySeconds != 10 {
+		t.Fatalf("Deployment got MinReadySeconds %d, wanted 10", dp.Spec.MinReadySeconds)
 	}
+	if *dp.Spec.ProgressDeadlineSeconds != deadline {
+		t.Fatalf("Deployment got ProgressDeadlineSeconds %d, wanted %d", *dp.Spec.ProgressDeadlineSeconds, deadline)
+	}
+}
 
+func TestDeploymentsFromApplication(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes = append(app.Spec.Processes, appv1alpha1.ProcessSpec{
+		Name:     "worker",
+		Replicas: 2,
+		Image:    testImage,
+	})
+
+	deployments := deploymentsFromApplication(app)
+
+	if l := len(deployments); l != 2 {
+		t.Fatalf("deploymentsFromApplication() got %d deployments, wanted 2", l)
+	}
+	worker := deployments[1]
+	if worker.Name != testAppName+"-worker" {
+		t.Fatalf("Deployment got name %s, wanted %s", worker.Name, testAppName+"-worker")
+	}
+	wantedSelector := map[string]string{
+		"app.kubernetes.io/name":      testAppName,
+		"app.kubernetes.io/component": "worker",
+		"app.bigkevmcd.com/track":     "stable",
+	}
+	if !reflect.DeepEqual(worker.Spec.Selector.MatchLabels, wantedSelector) {
+		t.Fatalf("Deployment got %#v MatchLabels, wanted %#v", worker.Spec.Selector.MatchLabels, wantedSelector)
+	}
 }
 
 func TestServiceFromApplication(t *testing.T) {
@@ -128,22 +290,110 @@ func TestServiceFromApplication(t *testing.T) {
 
 	wanted := []corev1.ServicePort{
 		{
-			Protocol: corev1.ProtocolTCP,
-			Port:     80,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       80,
+			TargetPort: intstr.FromInt(80),
 		},
 	}
 	if !reflect.DeepEqual(svc.Spec.Ports, wanted) {
 		t.Fatalf("Service got ports %#v, wanted %#v", svc.Spec.Ports, wanted)
 	}
 
-	if !reflect.DeepEqual(svc.Spec.Selector, testLabels) {
-		t.Fatalf("Service got selector %#v, wanted %#v", svc.Spec.Selector, testLabels)
+	if !reflect.DeepEqual(svc.Spec.Selector, testSelector) {
+		t.Fatalf("Service got selector %#v, wanted %#v", svc.Spec.Selector, testSelector)
 	}
 	if svc.Spec.Type != corev1.ServiceTypeNodePort {
 		t.Fatalf("Service got type %s, wanted %s", svc.Spec.Type, corev1.ServiceTypeNodePort)
 	}
 }
 
+func TestResourceNamesWithNamePrefix(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.NamePrefix = "shop"
+
+	names := map[string]string{
+		"configmap":  configMapNameForApp(app),
+		"deployment": deploymentNameForProcess(app, testProcess),
+		"service":    serviceNameForApp(app),
+		"cronjob":    cronJobNameForProcess(app, testProcess),
+		"pdb":        podDisruptionBudgetNameForProcess(app, testProcess),
+	}
+	wanted := map[string]string{
+		"configmap":  "shop-config-" + configHash(app),
+		"deployment": "shop-web",
+		"service":    "shop",
+		"cronjob":    "shop-web",
+		"pdb":        "shop-web",
+	}
+	if !reflect.DeepEqual(names, wanted) {
+		t.Fatalf("got names %#v, wanted %#v", names, wanted)
+	}
+	// The main container keeps the Application's name.
+	if name := makePodSpec(app, testProcess).Containers[0].Name; name != testAppName+"-web" {
+		t.Fatalf("got container name %s, wanted %s", name, testAppName+"-web")
+	}
+	// The selectors are unchanged, so that pods aren't orphaned.
+	if selector := makeLabelSelector(app, testProcess).MatchLabels; !reflect.DeepEqual(selector, testSelector) {
+		t.Fatalf("got selector %#v, wanted %#v", selector, testSelector)
+	}
+}
+
+func TestLabelsForAppPropagatesApplicationLabels(t *testing.T) {
+	app := makeTestApplication()
+	app.ObjectMeta.Labels = map[string]string{
+		"team":                      "accounts",
+		"app.kubernetes.io/part-of": "billing",
+		"app.kubernetes.io/name":    "not-the-name",
+	}
+
+	labels := labelsForApp(app)
+
+	wanted := map[string]string{
+		"team":                         "accounts",
+		"app.kubernetes.io/name":       testAppName,
+		"app.kubernetes.io/instance":   testAppName,
+		"app.kubernetes.io/part-of":    "billing",
+		"app.kubernetes.io/managed-by": "applications",
+	}
+	if !reflect.DeepEqual(labels, wanted) {
+		t.Fatalf("labelsForApp() got %#v, wanted %#v", labels, wanted)
+	}
+}
+
+func TestAnnotationsForApp(t *testing.T) {
+	app := makeTestApplication()
+	app.ObjectMeta.Annotations = map[string]string{
+		"example.com/owner": "accounts-team",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	}
+
+	cm := configMapFromApplication(app)
+
+	wanted := map[string]string{"example.com/owner": "accounts-team"}
+	if !reflect.DeepEqual(cm.Annotations, wanted) {
+		t.Fatalf("ConfigMap got annotations %#v, wanted %#v", cm.Annotations, wanted)
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	versionTests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.17.4", "1.17.4"},
+		{"nginx", ""},
+		{"registry.example.com:5000/nginx", ""},
+		{"registry.example.com:5000/nginx:1.17.4", "1.17.4"},
+		{"nginx@sha256:e4f0474a75c510f40b37b6b7dc2516241ffa8bde5a442bde3d372c9519c84d90", ""},
+	}
+
+	for _, tt := range versionTests {
+		if v := imageVersion(tt.image); v != tt.want {
+			t.Errorf("imageVersion(%q) got %q, wanted %q", tt.image, v, tt.want)
+		}
+	}
+}
+
 func makeTestApplication() *appv1alpha1.Application {
 	return &appv1alpha1.Application{
 		ObjectMeta: metav1.ObjectMeta{
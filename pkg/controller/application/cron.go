@@ -0,0 +1,216 @@
+package application
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearchDays limits how far back and forward a cron schedule is
+// searched for its activations, this is far enough for schedules that only
+// start on the 29th of February.
+const maxCronSearchDays = 366 * 8
+
+// cronFields are the minimum and maximum values of the minute, hour, day of
+// month, month and day of week fields, 7 is also Sunday, and the names that
+// can be used for the values of the month and day of week.
+var cronFields = [5]struct {
+	min, max int
+	names    map[string]int
+}{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cronMacros are the expressions that the macros accepted by CronJobs
+// replace.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed cron expression with the standard five fields.
+//
+// Each field is a set of values, with a bit set for each value.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// If either of the day fields is unrestricted, both must match,
+	// otherwise either can match.
+	domStar, dowStar bool
+}
+
+// parseCron parses a cron expression, each field can be "*" or "?", a value,
+// a range "1-5", or a list "1,3,5", with an optional step "*/15".
+//
+// Months and days of the week can be given by their names, "JAN" or "MON",
+// and the expression can be one of the macros "@yearly", "@annually",
+// "@monthly", "@weekly", "@daily", "@midnight" or "@hourly".
+//
+// This accepts the same expressions as a CronJob's schedule, except "@every",
+// which isn't tied to the time of day.
+func parseCron(s string) (*cronSchedule, error) {
+	expr := s
+	if strings.HasPrefix(s, "@") {
+		m, ok := cronMacros[strings.ToLower(s)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cron macro %q", s)
+		}
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", s)
+	}
+	values := [5]uint64{}
+	for i, f := range fields {
+		v, err := parseCronField(f, cronFields[i].min, cronFields[i].max, cronFields[i].names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", s, err)
+		}
+		values[i] = v
+	}
+	dow := values[4]
+	if dow&(1<<7) != 0 {
+		dow |= 1
+	}
+	return &cronSchedule{
+		minute:  values[0],
+		hour:    values[1],
+		dom:     values[2],
+		month:   values[3],
+		dow:     dow,
+		domStar: isCronStar(fields[2]),
+		dowStar: isCronStar(fields[4]),
+	}, nil
+}
+
+func isCronStar(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], s
+		}
+		start, end := min, max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], names); err != nil {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+			if end, err = parseCronValue(bounds[1], names); err != nil {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := parseCronValue(rng, names)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rng)
+			}
+			start = v
+			// A value with a step, "5/15", runs to the maximum.
+			if step == 1 {
+				end = v
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is outside %d-%d", rng, min, max)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue returns the value of a number, or of one of the names, which
+// are case insensitive.
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// prev returns the latest activation of the schedule at or before t, in t's
+// location, or false if there isn't one.
+func (c *cronSchedule) prev(t time.Time) (time.Time, bool) {
+	for i := 0; i < maxCronSearchDays; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()-i, 0, 0, 0, 0, t.Location())
+		if !c.matchesDay(day) {
+			continue
+		}
+		for h := 23; h >= 0; h-- {
+			for m := 59; m >= 0; m-- {
+				if c.matchesTime(h, m) {
+					at := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, t.Location())
+					if !at.After(t) {
+						return at, true
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// next returns the earliest activation of the schedule after t, in t's
+// location, or false if there isn't one.
+func (c *cronSchedule) next(t time.Time) (time.Time, bool) {
+	for i := 0; i < maxCronSearchDays; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+i, 0, 0, 0, 0, t.Location())
+		if !c.matchesDay(day) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			for m := 0; m < 60; m++ {
+				if c.matchesTime(h, m) {
+					at := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, t.Location())
+					if at.After(t) {
+						return at, true
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c *cronSchedule) matchesTime(hour, minute int) bool {
+	return c.hour&(1<<uint(hour)) != 0 && c.minute&(1<<uint(minute)) != 0
+}
+
+func (c *cronSchedule) matchesDay(day time.Time) bool {
+	if c.month&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<uint(day.Day())) != 0
+	dow := c.dow&(1<<uint(day.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	invalidTests := []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * FOO *",
+		"* * * * MON-FOO",
+		"@every 1h",
+		"@sometimes",
+	}
+
+	for _, s := range invalidTests {
+		if _, err := parseCron(s); err == nil {
+			t.Errorf("parseCron(%q) didn't fail", s)
+		}
+	}
+}
+
+func TestCronSchedule(t *testing.T) {
+	// This is a Wednesday.
+	now := time.Date(2019, time.October, 16, 12, 30, 0, 0, time.UTC)
+	cronTests := []struct {
+		schedule   string
+		wantedPrev time.Time
+		wantedNext time.Time
+	}{
+		{"*/15 * * * *", time.Date(2019, time.October, 16, 12, 30, 0, 0, time.UTC), time.Date(2019, time.October, 16, 12, 45, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2019, time.October, 16, 8, 0, 0, 0, time.UTC), time.Date(2019, time.October, 17, 8, 0, 0, 0, time.UTC)},
+		{"0 20 * * *", time.Date(2019, time.October, 15, 20, 0, 0, 0, time.UTC), time.Date(2019, time.October, 16, 20, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0,6", time.Date(2019, time.October, 13, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, time.October, 13, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2019, time.October, 11, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC), time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8 ? * MON-FRI", time.Date(2019, time.October, 16, 8, 0, 0, 0, time.UTC), time.Date(2019, time.October, 17, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,Jul *", time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,SUN", time.Date(2019, time.October, 13, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 19, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, time.October, 13, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 20, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, time.October, 16, 0, 0, 0, 0, time.UTC), time.Date(2019, time.October, 17, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, time.October, 16, 12, 0, 0, 0, time.UTC), time.Date(2019, time.October, 16, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range cronTests {
+		c, err := parseCron(tt.schedule)
+		if err != nil {
+			t.Errorf("parseCron(%q) failed: %s", tt.schedule, err)
+			continue
+		}
+		if prev, _ := c.prev(now); !prev.Equal(tt.wantedPrev) {
+			t.Errorf("%q prev got %s, wanted %s", tt.schedule, prev, tt.wantedPrev)
+		}
+		if next, _ := c.next(now); !next.Equal(tt.wantedNext) {
+			t.Errorf("%q next got %s, wanted %s", tt.schedule, next, tt.wantedNext)
+		}
+	}
+}
+
+func TestCronScheduleNeverStarts(t *testing.T) {
+	c, err := parseCron("0 0 30 2 *")
+	fatalIfError(t, "failed to parse", err)
+
+	if _, ok := c.next(time.Now()); ok {
+		t.Fatal("next found a 30th of February")
+	}
+}
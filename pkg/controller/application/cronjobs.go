@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// isScheduled returns true if the process is run as a CronJob rather than a
+// Deployment.
+func isScheduled(p appv1alpha1.ProcessSpec) bool {
+	return p.Schedule != ""
+}
+
+// cronJobsFromApplication makes a CronJob for each of the scheduled processes
+// in the Application.
+func cronJobsFromApplication(app *appv1alpha1.Application) []*batchv1beta1.CronJob {
+	cronJobs := []*batchv1beta1.CronJob{}
+	for _, p := range app.Spec.Processes {
+		if isScheduled(p) {
+			cronJobs = append(cronJobs, cronJobFromProcess(app, p))
+		}
+	}
+	return cronJobs
+}
+
+// cronJobFromProcess makes a CronJob for a scheduled process, the pods are
+// configured in the same way as the pods for the other processes.
+//
+// Sidecars are not included, as the Jobs wouldn't complete while they run.
+func cronJobFromProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) *batchv1beta1.CronJob {
+	p.Sidecars = nil
+	spec := makePodSpec(app, p)
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	suspend := app.Spec.Suspend
+	return &batchv1beta1.CronJob{
+		ObjectMeta: makeProcessObjectMeta(cronJobNameForProcess(app, p), app, p),
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   p.Schedule,
+			Suspend:                    &suspend,
+			ConcurrencyPolicy:          p.ConcurrencyPolicy,
+			SuccessfulJobsHistoryLimit: p.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     p.FailedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: makeProcessObjectMeta("", app, p),
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: makePodTemplateObjectMeta(app, p),
+						Spec:       spec,
+					},
+				},
+			},
+		},
+	}
+}
+
+func cronJobNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return resourceName(app, p.Name)
+}
+
+// validateSchedules returns an error if the primary process is scheduled, as
+// it receives the traffic from the Application's Service, or if a schedule
+// can't be parsed.
+//
+// Schedules are parsed in the same way as scaling schedules.
+func validateSchedules(a *appv1alpha1.Application) error {
+	if p := primaryProcess(a); isScheduled(p) {
+		return fmt.Errorf("process %s receives traffic from the Service and can't have a schedule", p.Name)
+	}
+	for _, p := range a.Spec.Processes {
+		if !isScheduled(p) {
+			continue
+		}
+		if _, err := parseCron(p.Schedule); err != nil {
+			return fmt.Errorf("process %s: %s", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// reconcileCronJobs creates or updates the CronJobs for the scheduled
+// processes, and deletes the CronJobs for processes that are no longer
+// scheduled.
+func (r *ReconcileApplication) reconcileCronJobs(a *appv1alpha1.Application, logger logr.Logger) error {
+	current := map[string]bool{}
+	for _, cj := range cronJobsFromApplication(a) {
+		current[cj.Name] = true
+		err := r.createOrUpdateCronJob(a, cj, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	cronJobs := &batchv1beta1.CronJobList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), cronJobs)
+	if err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		cj := &cronJobs.Items[i]
+		if !metav1.IsControlledBy(cj, a) || current[cj.Name] {
+			continue
+		}
+		logger.Info("Deleting CronJob", "Deleted.Namespace", cj.Namespace, "Deleted.Name", cj.Name)
+		err = r.client.Delete(context.TODO(), cj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileApplication) createOrUpdateCronJob(a *appv1alpha1.Application, cronJob *batchv1beta1.CronJob, logger logr.Logger) error {
+	err := controllerutil.SetControllerReference(a, cronJob, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	found := &batchv1beta1.CronJob{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cronJob.Name, Namespace: cronJob.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new CronJob", "Created.Namespace", cronJob.Namespace, "Created.Name", cronJob.Name)
+		return r.client.Create(context.TODO(), cronJob)
+	} else if err != nil {
+		return err
+	}
+
+	logger.Info("Updating existing CronJob", "Updated.Namespace", cronJob.Namespace, "Updated.Name", cronJob.Name)
+	found.Labels = cronJob.Labels
+	found.Annotations = mergeAnnotations(found.Annotations, cronJob.Annotations)
+	found.Spec = cronJob.Spec
+	return r.client.Update(context.TODO(), found)
+}
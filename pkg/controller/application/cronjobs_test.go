@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var testClockProcess = appv1alpha1.ProcessSpec{
+	Name:              "clock",
+	Image:             testImage,
+	Schedule:          "*/15 * * * *",
+	ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+}
+
+func TestCronJobFromProcessWithoutSidecars(t *testing.T) {
+	app := makeTestApplication()
+	process := testClockProcess
+	process.InitContainers = []appv1alpha1.ContainerSpec{{Name: "setup", Image: "setup:latest"}}
+	process.Sidecars = []appv1alpha1.ContainerSpec{{Name: "proxy", Image: "proxy:latest"}}
+
+	cj := cronJobFromProcess(app, process)
+
+	spec := cj.Spec.JobTemplate.Spec.Template.Spec
+	if l := len(spec.Containers); l != 1 {
+		t.Fatalf("CronJob got %d containers, wanted 1", l)
+	}
+	if l := len(spec.InitContainers); l != 1 {
+		t.Fatalf("CronJob got %d init containers, wanted 1", l)
+	}
+}
+
+func TestCronJobFromProcess(t *testing.T) {
+	app := makeTestApplication()
+	limit := int32(2)
+	process := testClockProcess
+	process.FailedJobsHistoryLimit = &limit
+
+	cj := cronJobFromProcess(app, process)
+
+	if cj.Name != testAppName+"-clock" {
+		t.Fatalf("CronJob got name %s, wanted %s", cj.Name, testAppName+"-clock")
+	}
+	if cj.Spec.Schedule != process.Schedule {
+		t.Fatalf("CronJob got schedule %s, wanted %s", cj.Spec.Schedule, process.Schedule)
+	}
+	if cj.Spec.ConcurrencyPolicy != batchv1beta1.ForbidConcurrent {
+		t.Fatalf("CronJob got ConcurrencyPolicy %s, wanted %s", cj.Spec.ConcurrencyPolicy, batchv1beta1.ForbidConcurrent)
+	}
+	if !reflect.DeepEqual(cj.Spec.FailedJobsHistoryLimit, &limit) {
+		t.Fatalf("CronJob got FailedJobsHistoryLimit %#v, wanted %#v", cj.Spec.FailedJobsHistoryLimit, &limit)
+	}
+	spec := cj.Spec.JobTemplate.Spec.Template.Spec
+	wanted := makePodSpec(app, process)
+	wanted.RestartPolicy = corev1.RestartPolicyNever
+	if !reflect.DeepEqual(spec, wanted) {
+		t.Fatalf("CronJob got pod spec %#v, wanted %#v", spec, wanted)
+	}
+}
+
+func TestReconcileScheduledProcess(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes = append(app.Spec.Processes, testClockProcess)
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	fatalIfError(t, "failed to get cronjob", cl.Get(context.TODO(), ns(testAppName+"-clock", testNamespace), &batchv1beta1.CronJob{}))
+	assertNotFound(t, cl, ns(testAppName+"-clock", testNamespace), &appsv1.Deployment{})
+}
+
+func TestReconcileRemovesUnscheduledCronJobs(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes = append(app.Spec.Processes, testClockProcess)
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	updated.Spec.Processes = updated.Spec.Processes[:1]
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), updated))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testAppName+"-clock", testNamespace), &batchv1beta1.CronJob{})
+}
+
+func TestReconcileScheduledPrimaryProcessIsInvalid(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].Schedule = "@hourly"
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+}
+
+func TestValidateSchedules(t *testing.T) {
+	validationTests := []struct {
+		schedule string
+		valid    bool
+	}{
+		{"*/15 * * * *", true},
+		{"0 8 * * MON-FRI", true},
+		{"0 0 1 JAN *", true},
+		{"@daily", true},
+		{"@hourly", true},
+		{"0 8 * *", false},
+		{"@every 1h", false},
+	}
+
+	for _, tt := range validationTests {
+		app := makeTestApplication()
+		clock := testClockProcess
+		clock.Schedule = tt.schedule
+		app.Spec.Processes = append(app.Spec.Processes, clock)
+		if err := validateSchedules(app); (err == nil) != tt.valid {
+			t.Errorf("validateSchedules(%q) got %v, wanted valid %v", tt.schedule, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// reconcileDrift records the resources that have been changed by something
+// other than the operator.
+//
+// Changes are only looked for once the resources for the Application's
+// current spec have been written, until then the differences are from the new
+// spec, and the resources are updated.
+//
+// If the Application's DriftPolicy is Report, the drift is recorded in the
+// status, and the drifted resources are left alone, otherwise the changes are
+// reverted.
+func (r *ReconcileApplication) reconcileDrift(a *appv1alpha1.Application, logger logr.Logger) error {
+	drift, err := r.detectDrift(a)
+	if err != nil {
+		return err
+	}
+	if a.Spec.DriftPolicy == appv1alpha1.DriftReport {
+		r.reportDrift(a, drift)
+		return nil
+	}
+	for _, d := range drift {
+		logger.Info("Reverting drift", "Kind", d.Kind, "Name", d.Name, "Fields", d.Fields)
+		r.recorder.Eventf(a, corev1.EventTypeNormal, "DriftReverted", "Reverting changes to %s %s: %s", d.Kind, d.Name, strings.Join(d.Fields, ", "))
+	}
+	a.Status.Drift = nil
+	return nil
+}
+
+// reportDrift records the drift in the Application's status, with an Event
+// for each resource that has drifted since it was last recorded.
+func (r *ReconcileApplication) reportDrift(a *appv1alpha1.Application, drift []appv1alpha1.ResourceDrift) {
+	for _, d := range drift {
+		previous := findDrift(a.Status.Drift, d.Kind, d.Name)
+		if previous != nil && reflect.DeepEqual(previous.Fields, d.Fields) {
+			continue
+		}
+		r.recorder.Eventf(a, corev1.EventTypeWarning, "Drifted", "%s %s differs from the Application: %s", d.Kind, d.Name, strings.Join(d.Fields, ", "))
+	}
+	if len(drift) == 0 {
+		drift = nil
+	}
+	a.Status.Drift = drift
+}
+
+// driftIgnored returns true if the resource has drifted, and the Application
+// only reports drift.
+func driftIgnored(a *appv1alpha1.Application, kind, name string) bool {
+	return a.Spec.DriftPolicy == appv1alpha1.DriftReport && findDrift(a.Status.Drift, kind, name) != nil
+}
+
+func findDrift(drift []appv1alpha1.ResourceDrift, kind, name string) *appv1alpha1.ResourceDrift {
+	for i := range drift {
+		if drift[i].Kind == kind && drift[i].Name == name {
+			return &drift[i]
+		}
+	}
+	return nil
+}
+
+// detectDrift returns the ConfigMap, Deployments and Service that differ from
+// what the Application describes.
+//
+// Nothing is returned if the resources for the Application's current spec
+// haven't been written.
+//
+// The Deployments for the blue/green colours, CronJobs, PodDisruptionBudgets
+// and NetworkPolicies aren't checked, changes to them are reverted when the
+// Application is next reconciled.
+func (r *ReconcileApplication) detectDrift(a *appv1alpha1.Application) ([]appv1alpha1.ResourceDrift, error) {
+	if a.Status.AppliedHash != appliedHash(a) {
+		return nil, nil
+	}
+	drift := []appv1alpha1.ResourceDrift{}
+	addDrift := func(kind, name string, fields []string) {
+		if len(fields) > 0 {
+			drift = append(drift, appv1alpha1.ResourceDrift{Kind: kind, Name: name, Fields: fields})
+		}
+	}
+
+	configMap := configMapFromApplication(a)
+	foundConfigMap := &corev1.ConfigMap{}
+	found, err := r.getResource(configMap.Name, a.Namespace, foundConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		addDrift("ConfigMap", configMap.Name, driftedData(configMap.Data, foundConfigMap.Data))
+	}
+
+	// The replicas of processes with scaling schedules change without the
+	// Application changing.
+	scheduled := map[string]bool{}
+	for _, p := range a.Spec.Processes {
+		if len(p.ScalingSchedules) > 0 {
+			scheduled[deploymentNameForProcess(a, p)] = true
+			scheduled[canaryDeploymentNameForProcess(a, p)] = true
+		}
+	}
+	for _, d := range deploymentsFromApplication(a) {
+		foundDeployment := &appsv1.Deployment{}
+		found, err := r.getResource(d.Name, a.Namespace, foundDeployment)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if scheduled[d.Name] {
+			d.Spec.Replicas = nil
+		}
+		addDrift("Deployment", d.Name, driftedFields("spec", reflect.ValueOf(d.Spec), reflect.ValueOf(foundDeployment.Spec)))
+	}
+
+	// The Service keeps the old selector while Deployments are migrated.
+	retired, err := r.retiredDeployments(a)
+	if err != nil || len(retired) > 0 {
+		return drift, err
+	}
+	service := serviceFromApplication(a)
+	foundService := &corev1.Service{}
+	found, err = r.getResource(service.Name, a.Namespace, foundService)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		addDrift("Service", service.Name, driftedFields("spec", reflect.ValueOf(service.Spec), reflect.ValueOf(foundService.Spec)))
+	}
+	return drift, nil
+}
+
+// appliedHash returns a hash of the ConfigMap, Deployments and Service for
+// the Application.
+//
+// This is recorded in the status when they have been written, so that
+// differences from a spec that hasn't been rolled out aren't drift.
+func appliedHash(a *appv1alpha1.Application) string {
+	b, err := json.Marshal([]interface{}{
+		configMapFromApplication(a),
+		deploymentsFromApplication(a),
+		serviceFromApplication(a),
+	})
+	if err != nil {
+		// Marshalling the resources can't fail.
+		panic(err)
+	}
+	return hashString(string(b))
+}
+
+// driftedData returns the keys in the ConfigMap data that have been added,
+// removed or changed.
+func driftedData(desired, existing map[string]string) []string {
+	fields := []string{}
+	for _, k := range sortedKeys(desired) {
+		if v, ok := existing[k]; !ok || v != desired[k] {
+			fields = append(fields, fmt.Sprintf("data[%s]", k))
+		}
+	}
+	for _, k := range sortedKeys(existing) {
+		if _, ok := desired[k]; !ok {
+			fields = append(fields, fmt.Sprintf("data[%s]", k))
+		}
+	}
+	return fields
+}
+
+// driftedFields returns the paths of the fields that are set in desired and
+// have different values in existing.
+//
+// Zero values in desired are treated as unset, so that the defaults filled
+// in by the API server aren't reported, and additional items in existing
+// slices, for example injected containers, are ignored.
+//
+// Values that are marshalled to JSON as a whole, like quantities, are
+// compared without looking at their fields.
+func driftedFields(path string, desired, existing reflect.Value) []string {
+	if isZero(desired) {
+		return nil
+	}
+	switch desired.Kind() {
+	case reflect.Ptr:
+		if existing.IsNil() {
+			return []string{path}
+		}
+		return driftedFields(path, desired.Elem(), existing.Elem())
+	case reflect.Struct:
+		if desired.Type().Implements(jsonMarshalerType) || reflect.PtrTo(desired.Type()).Implements(jsonMarshalerType) {
+			break
+		}
+		fields := []string{}
+		for i := 0; i < desired.NumField(); i++ {
+			name, ok := jsonFieldName(desired.Type().Field(i))
+			if !ok {
+				continue
+			}
+			fields = append(fields, driftedFields(joinPath(path, name), desired.Field(i), existing.Field(i))...)
+		}
+		return fields
+	case reflect.Slice:
+		if desired.Len() > existing.Len() {
+			return []string{path}
+		}
+		fields := []string{}
+		for i := 0; i < desired.Len(); i++ {
+			fields = append(fields, driftedFields(fmt.Sprintf("%s[%d]", path, i), desired.Index(i), existing.Index(i))...)
+		}
+		return fields
+	case reflect.Map:
+		keys := desired.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		fields := []string{}
+		for _, k := range keys {
+			keyPath := fmt.Sprintf("%s[%v]", path, k)
+			v := existing.MapIndex(k)
+			if !v.IsValid() {
+				fields = append(fields, keyPath)
+				continue
+			}
+			fields = append(fields, driftedFields(keyPath, desired.MapIndex(k), v)...)
+		}
+		return fields
+	}
+	if equality.Semantic.DeepEqual(desired.Interface(), existing.Interface()) {
+		return nil
+	}
+	return []string{path}
+}
+
+// isZero returns true for nil pointers, empty slices and maps, and zero
+// values.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// jsonFieldName returns the name of the field in JSON, an empty name for
+// inlined fields, or false if the field isn't marshalled.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" && !f.Anonymous {
+		name = f.Name
+	}
+	return name, true
+}
+
+func joinPath(path, name string) string {
+	if name == "" {
+		return path
+	}
+	return path + "." + name
+}
+
+// getResource fetches the named resource into obj, returning false if it
+// doesn't exist.
+func (r *ReconcileApplication) getResource(name, namespace string, obj runtime.Object) (bool, error) {
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, obj)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestDriftedFields(t *testing.T) {
+	app := makeTestApplication()
+	desired := deploymentFromProcess(app, testProcess)
+	existing := desired.DeepCopy()
+	// Defaults filled in by the API server aren't drift.
+	revisionHistoryLimit := int32(10)
+	existing.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+	existing.Spec.Template.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+	replicas := int32(1)
+	existing.Spec.Replicas = &replicas
+	existing.Spec.Template.Spec.Containers[0].Image = "example/web:hotfix"
+
+	fields := driftedFields("spec", reflect.ValueOf(desired.Spec), reflect.ValueOf(existing.Spec))
+
+	want := []string{"spec.replicas", "spec.template.spec.containers[0].image"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("driftedFields() got %#v, wanted %#v", fields, want)
+	}
+}
+
+func TestDriftedData(t *testing.T) {
+	desired := map[string]string{"A": "1", "B": "2"}
+	existing := map[string]string{"A": "1", "B": "3", "C": "4"}
+
+	fields := driftedData(desired, existing)
+
+	want := []string{"data[B]", "data[C]"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("driftedData() got %#v, wanted %#v", fields, want)
+	}
+}
+
+func TestDriftIsReverted(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	scaleDeployment(t, cl, testDeploymentName, 1)
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	assertEvent(t, recorder, "DriftReverted")
+	if drift := getApplicationDrift(t, cl); len(drift) != 0 {
+		t.Fatalf("got drift %#v, wanted none", drift)
+	}
+}
+
+func TestDriftIsReported(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.DriftPolicy = appv1alpha1.DriftReport
+	r, cl := createApplicationReconciler(t, app)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	scaleDeployment(t, cl, testDeploymentName, 1)
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 1)
+	assertEvent(t, recorder, "Drifted")
+	want := []appv1alpha1.ResourceDrift{
+		{Kind: "Deployment", Name: testDeploymentName, Fields: []string{"spec.replicas"}},
+	}
+	if drift := getApplicationDrift(t, cl); !reflect.DeepEqual(drift, want) {
+		t.Fatalf("got drift %#v, wanted %#v", drift, want)
+	}
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	select {
+	case e := <-recorder.Events:
+		t.Fatalf("got event %q for drift that was already reported", e)
+	default:
+	}
+}
+
+func TestUnreleasedChangesAreNotDrift(t *testing.T) {
+	app := makeReleaseApplication()
+	app.Spec.DriftPolicy = appv1alpha1.DriftReport
+	r, cl := createApplicationReconciler(t, app)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Processes[0].Image = "test-image:v2"
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	for i := 0; i < 2; i++ {
+		_, err = r.Reconcile(req)
+		fatalIfError(t, "failed to reconcile", err)
+	}
+
+	select {
+	case e := <-recorder.Events:
+		t.Fatalf("got event %q for a change that hasn't been released", e)
+	default:
+	}
+	if drift := getApplicationDrift(t, cl); len(drift) != 0 {
+		t.Fatalf("got drift %#v, wanted none", drift)
+	}
+
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	if image := d.Spec.Template.Spec.Containers[0].Image; image != "test-image:v2" {
+		t.Fatalf("Deployment got image %s, wanted test-image:v2", image)
+	}
+}
+
+func getApplicationDrift(t *testing.T, cl client.Client) []appv1alpha1.ResourceDrift {
+	t.Helper()
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	return app.Status.Drift
+}
+
+func assertEvent(t *testing.T, recorder *record.FakeRecorder, reason string) {
+	t.Helper()
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, reason) {
+			t.Fatalf("got event %q, wanted %s", e, reason)
+		}
+	default:
+		t.Fatalf("no event recorded, wanted %s", reason)
+	}
+}
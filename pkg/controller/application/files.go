@@ -0,0 +1,94 @@
+package application
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	filesVolumeName      = "application-files"
+	configHashAnnotation = "app.bigkevmcd.com/config-hash"
+	fileKeyPrefix        = "file."
+)
+
+// fileKey returns the key in the ConfigMap for the file at a path.
+//
+// The files share the ConfigMap with the environment, validateEnvironment
+// rejects environment variables with the same prefix, so that they can't
+// clash.
+func fileKey(path string) string {
+	return fileKeyPrefix + hashString(path)
+}
+
+// validateEnvironment returns an error if an environment variable would be
+// stored with the same key as a file in the Application's ConfigMap.
+func validateEnvironment(a *appv1alpha1.Application) error {
+	for _, k := range sortedKeys(a.Spec.Environment) {
+		if strings.HasPrefix(k, fileKeyPrefix) {
+			return fmt.Errorf("environment variable %s can't start with %q, it's used for files", k, fileKeyPrefix)
+		}
+	}
+	return nil
+}
+
+// makeFilesVolume returns the volume that contains the Application's files,
+// or nil if there are no files.
+func makeFilesVolume(app *appv1alpha1.Application) *corev1.Volume {
+	if len(app.Spec.Files) == 0 {
+		return nil
+	}
+	items := []corev1.KeyToPath{}
+	for _, path := range sortedKeys(app.Spec.Files) {
+		items = append(items, corev1.KeyToPath{Key: fileKey(path), Path: fileKey(path)})
+	}
+	return &corev1.Volume{
+		Name: filesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: configMapNameForApp(app),
+				},
+				Items: items,
+			},
+		},
+	}
+}
+
+// makeFilesVolumeMounts returns a mount for each of the Application's files.
+func makeFilesVolumeMounts(app *appv1alpha1.Application) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, path := range sortedKeys(app.Spec.Files) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      filesVolumeName,
+			MountPath: path,
+			SubPath:   fileKey(path),
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
+
+// configHash returns a hash of the data in the Application's ConfigMap.
+//
+// This is added to the pod template, so that changing the environment or
+// files rolls out new pods.
+func configHash(app *appv1alpha1.Application) string {
+	data := configDataForApp(app)
+	h := fnv.New32a()
+	for _, k := range sortedKeys(data) {
+		fmt.Fprintf(h, "%s=%s\x00", k, data[k])
+	}
+	return rand.SafeEncodeString(fmt.Sprint(h.Sum32()))
+}
+
+func hashString(s string) string {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return rand.SafeEncodeString(fmt.Sprint(h.Sum32()))
+}
@@ -0,0 +1,94 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestConfigMapFromApplicationWithFiles(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Files = map[string]string{"/etc/app/config.yaml": "debug: true\n"}
+
+	cm := configMapFromApplication(app)
+
+	wanted := map[string]string{fileKey("/etc/app/config.yaml"): "debug: true\n"}
+	for k, v := range testEnvironment {
+		wanted[k] = v
+	}
+	if !reflect.DeepEqual(cm.Data, wanted) {
+		t.Fatalf("configMapFromApplication() got Data %#v, wanted %#v", cm.Data, wanted)
+	}
+}
+
+func TestMakePodSpecMountsFiles(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Files = map[string]string{"/etc/app/config.yaml": "debug: true\n"}
+	key := fileKey("/etc/app/config.yaml")
+
+	s := makePodSpec(app, testProcess)
+
+	wantedVolumes := []corev1.Volume{
+		{
+			Name: filesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: testAppName + "-config-" + configHash(app)},
+					Items:                []corev1.KeyToPath{{Key: key, Path: key}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(s.Volumes, wantedVolumes) {
+		t.Fatalf("makePodSpec() got Volumes %#v, wanted %#v", s.Volumes, wantedVolumes)
+	}
+	wantedMounts := []corev1.VolumeMount{
+		{Name: filesVolumeName, MountPath: "/etc/app/config.yaml", SubPath: key, ReadOnly: true},
+	}
+	if m := s.Containers[0].VolumeMounts; !reflect.DeepEqual(m, wantedMounts) {
+		t.Fatalf("makePodSpec() got VolumeMounts %#v, wanted %#v", m, wantedMounts)
+	}
+}
+
+func TestConfigChangesUpdatePodTemplate(t *testing.T) {
+	changes := []struct {
+		name   string
+		change func(*appv1alpha1.Application)
+	}{
+		{"environment", func(a *appv1alpha1.Application) { a.Spec.Environment = map[string]string{"new": "value"} }},
+		{"files", func(a *appv1alpha1.Application) { a.Spec.Files = map[string]string{"/etc/motd": "hello"} }},
+	}
+
+	for _, tt := range changes {
+		app := makeTestApplication()
+		before := deploymentFromProcess(app, testProcess).Spec.Template.Annotations[configHashAnnotation]
+		tt.change(app)
+
+		after := deploymentFromProcess(app, testProcess).Spec.Template.Annotations[configHashAnnotation]
+		if before == after {
+			t.Errorf("%s change: config hash %#v didn't change", tt.name, after)
+		}
+	}
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	envTests := []struct {
+		env   map[string]string
+		valid bool
+	}{
+		{map[string]string{"TEST_MODE": "true"}, true},
+		{map[string]string{"config.file": "app.yaml"}, true},
+		{map[string]string{fileKey("/etc/app/config.yaml"): "debug: false"}, false},
+	}
+
+	for _, tt := range envTests {
+		app := makeTestApplication()
+		app.Spec.Environment = tt.env
+		if err := validateEnvironment(app); (err == nil) != tt.valid {
+			t.Errorf("validateEnvironment(%#v) got error %v, wanted valid %v", tt.env, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// updateImageStatus records the digests of the images that the processes'
+// pods are running in the status.
+//
+// Only pods running the process's current image are used, so canaries are
+// ignored, and processes without running pods are left out.
+func (r *ReconcileApplication) updateImageStatus(a *appv1alpha1.Application) error {
+	images := map[string]string{}
+	for _, p := range a.Spec.Processes {
+		if isScheduled(p) {
+			continue
+		}
+		pods := &corev1.PodList{}
+		err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForProcess(a, p)), pods)
+		if err != nil {
+			return err
+		}
+		if image := runningImage(pods.Items, containerNameForProcess(a, p), p.Image); image != "" {
+			images[p.Name] = image
+		}
+	}
+	a.Status.Images = nil
+	if len(images) > 0 {
+		a.Status.Images = images
+	}
+	return nil
+}
+
+// runningImage returns the image by digest for the named container, from the
+// first pod that is running the image.
+func runningImage(pods []corev1.Pod, container, image string) string {
+	for _, pod := range pods {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name != container || !cs.Ready {
+				continue
+			}
+			if !podRunsImage(pod, container, image) {
+				continue
+			}
+			if digest := imageDigest(cs.ImageID); digest != "" {
+				return imageRepository(image) + "@" + digest
+			}
+		}
+	}
+	return ""
+}
+
+func podRunsImage(pod corev1.Pod, container, image string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return c.Image == image
+		}
+	}
+	return false
+}
+
+// imageDigest returns the digest from the image ID that the container
+// runtime reports, for example "docker-pullable://nginx@sha256:...".
+func imageDigest(imageID string) string {
+	i := strings.LastIndex(imageID, "@")
+	if i == -1 {
+		return ""
+	}
+	return imageID[i+1:]
+}
+
+// imageRepository returns the image reference without the tag or digest.
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i != -1 && !strings.Contains(image[i:], "/") {
+		image = image[:i]
+	}
+	return image
+}
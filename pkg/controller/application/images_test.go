@@ -0,0 +1,49 @@
+package application
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRunningImage(t *testing.T) {
+	pod := func(image, imageID string) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "test-app-web", Image: image}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "test-app-web", Image: image, ImageID: imageID, Ready: true},
+				},
+			},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("example/web:canary", "docker-pullable://example/web@sha256:canary"),
+		pod("registry:5000/example/web:v1", "docker-pullable://registry:5000/example/web@sha256:0123"),
+	}
+
+	image := runningImage(pods, "test-app-web", "registry:5000/example/web:v1")
+
+	if image != "registry:5000/example/web@sha256:0123" {
+		t.Fatalf("runningImage() got %q, wanted %q", image, "registry:5000/example/web@sha256:0123")
+	}
+}
+
+func TestRunningImageWithoutDigest(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "test-app-web", Image: testImage}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "test-app-web", Image: testImage, Ready: true}},
+			},
+		},
+	}
+
+	if image := runningImage(pods, "test-app-web", testImage); image != "" {
+		t.Fatalf("runningImage() got %q, wanted \"\"", image)
+	}
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// linkEnvName returns the name of the environment variable with the URL of a
+// linked Application, for example "ACCOUNTS_URL" for "accounts".
+func linkEnvName(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name)) + "_URL"
+}
+
+// linkURL returns the URL of an Application's Service.
+func linkURL(app *appv1alpha1.Application) string {
+	return fmt.Sprintf("http://%s:%d", serviceNameForApp(app), servicePortForApp(app))
+}
+
+// environmentForApp returns the Application's environment, with the URLs of
+// the linked Applications and the root of the addon bindings, the
+// Application's own environment takes precedence.
+func environmentForApp(app *appv1alpha1.Application) map[string]string {
+	env := map[string]string{}
+	for name, url := range app.Status.Links {
+		env[linkEnvName(name)] = url
+	}
+	if len(app.Spec.Addons) > 0 {
+		env[bindingsRootEnv] = bindingsRoot
+	}
+	for k, v := range app.Spec.Environment {
+		env[k] = v
+	}
+	return env
+}
+
+// resolveLinks records the URLs of the linked Applications in the status.
+//
+// Links to Applications that don't exist are left out, and reported in the
+// LinkMissing condition.
+func (r *ReconcileApplication) resolveLinks(a *appv1alpha1.Application) error {
+	links := map[string]string{}
+	missing := []string{}
+	for _, name := range a.Spec.Links {
+		target := &appv1alpha1.Application{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: a.Namespace}, target)
+		if errors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		links[name] = linkURL(target)
+	}
+	a.Status.Links = nil
+	if len(links) > 0 {
+		a.Status.Links = links
+	}
+
+	switch {
+	case len(missing) > 0:
+		sort.Strings(missing)
+		setCondition(&a.Status, appv1alpha1.ApplicationLinkMissing, corev1.ConditionTrue, "ApplicationNotFound",
+			fmt.Sprintf("linked Applications not found: %s", strings.Join(missing, ", ")))
+	case len(a.Spec.Links) > 0 || findCondition(&a.Status, appv1alpha1.ApplicationLinkMissing) != nil:
+		setCondition(&a.Status, appv1alpha1.ApplicationLinkMissing, corev1.ConditionFalse, "LinksResolved", "")
+	}
+	return nil
+}
+
+// linkingApplications returns a mapper from an Application to the
+// Applications in the same namespace that link to it, so that they are
+// reconciled when it changes.
+func linkingApplications(cl client.Client) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		apps := &appv1alpha1.ApplicationList{}
+		err := cl.List(context.TODO(), client.InNamespace(o.Meta.GetNamespace()), apps)
+		if err != nil {
+			log.Error(err, "failed to list Applications", "Namespace", o.Meta.GetNamespace())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, app := range apps.Items {
+			for _, name := range app.Spec.Links {
+				if name == o.Meta.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: app.Name, Namespace: app.Namespace},
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
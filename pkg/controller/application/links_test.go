@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestLinkEnvName(t *testing.T) {
+	nameTests := []struct {
+		name string
+		want string
+	}{
+		{"accounts", "ACCOUNTS_URL"},
+		{"user-service", "USER_SERVICE_URL"},
+		{"api.v2", "API_V2_URL"},
+	}
+
+	for _, tt := range nameTests {
+		if got := linkEnvName(tt.name); got != tt.want {
+			t.Errorf("linkEnvName(%q) got %q, wanted %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentForAppWithLinks(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Environment = map[string]string{"ACCOUNTS_URL": "http://override"}
+	app.Status.Links = map[string]string{"accounts": "http://accounts:80", "billing": "http://billing:80"}
+
+	env := environmentForApp(app)
+
+	wanted := map[string]string{"ACCOUNTS_URL": "http://override", "BILLING_URL": "http://billing:80"}
+	if !reflect.DeepEqual(env, wanted) {
+		t.Fatalf("environmentForApp() got %#v, wanted %#v", env, wanted)
+	}
+}
+
+func TestReconcileInjectsLinks(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Links = []string{"accounts", "billing"}
+	accounts := makeTestApplication()
+	accounts.Name = "accounts"
+	r, cl := createApplicationReconciler(t, app, accounts)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	wanted := map[string]string{"ACCOUNTS_URL": "http://accounts:80"}
+	for k, v := range testEnvironment {
+		wanted[k] = v
+	}
+	assertConfigMapHasData(t, testAppName, testNamespace, cl, wanted)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationLinkMissing, corev1.ConditionTrue)
+}
+
+func TestLinkingApplications(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Links = []string{"accounts"}
+	other := makeTestApplication()
+	other.Name = "other"
+	_, cl := createApplicationReconciler(t, app, other)
+	accounts := makeTestApplication()
+	accounts.Name = "accounts"
+
+	requests := linkingApplications(cl)(handler.MapObject{Meta: accounts, Object: accounts})
+
+	wanted := []reconcile.Request{makeRequest()}
+	if !reflect.DeepEqual(requests, wanted) {
+		t.Fatalf("linkingApplications() got %#v, wanted %#v", requests, wanted)
+	}
+}
+
+func TestReconcileRemovesLinks(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Links = []string{"accounts"}
+	app.Status.Links = map[string]string{"accounts": "http://accounts:80"}
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	if updated.Status.Links != nil {
+		t.Fatalf("got links %#v, wanted nil", updated.Status.Links)
+	}
+}
@@ -0,0 +1,247 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// migrationRequeueDelay is how long to wait before checking whether the
+// Deployments that replace older Deployments have become ready.
+const migrationRequeueDelay = time.Second * 10
+
+// replacedDeploymentAnnotation records the name of the Deployment that a
+// ReplicaSet was orphaned from by replaceDeployment, only these ReplicaSets
+// are removed by retireDeployments.
+const replacedDeploymentAnnotation = "app.bigkevmcd.com/replaced-deployment"
+
+// selectorsCompatible returns true if the existing Deployment can be updated
+// to the desired Deployment, the selector of a Deployment can't be changed.
+func selectorsCompatible(existing, desired *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(existing.Spec.Selector, desired.Spec.Selector)
+}
+
+// replaceDeployment deletes a Deployment that can't be updated in place.
+//
+// The ReplicaSets of the Deployment are orphaned, so that the existing pods
+// continue to run until the replacement Deployment is ready, and then they
+// are removed by retireDeployments.
+func (r *ReconcileApplication) replaceDeployment(d *appsv1.Deployment, logger logr.Logger) error {
+	if d.DeletionTimestamp != nil {
+		return nil
+	}
+	err := r.markReplicaSetsReplaced(d)
+	if err != nil {
+		return err
+	}
+	logger.Info("Replacing Deployment with incompatible selector", "Replaced.Namespace", d.Namespace, "Replaced.Name", d.Name)
+	err = r.client.Delete(context.TODO(), d, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// markReplicaSetsReplaced annotates the ReplicaSets controlled by the
+// Deployment, so that they can be found once they're orphaned.
+func (r *ReconcileApplication) markReplicaSetsReplaced(d *appsv1.Deployment) error {
+	replicaSets := &appsv1.ReplicaSetList{}
+	err := r.client.List(context.TODO(), client.InNamespace(d.Namespace).MatchingLabels(d.Spec.Selector.MatchLabels), replicaSets)
+	if err != nil {
+		return err
+	}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if !metav1.IsControlledBy(rs, d) || rs.Annotations[replacedDeploymentAnnotation] == d.Name {
+			continue
+		}
+		if rs.Annotations == nil {
+			rs.Annotations = map[string]string{}
+		}
+		rs.Annotations[replacedDeploymentAnnotation] = d.Name
+		err = r.client.Update(context.TODO(), rs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retireDeployments removes the Deployments and orphaned ReplicaSets that
+// were created for an earlier configuration of the Application, but only
+// once all the Deployments for the current configuration are ready.
+//
+// It returns true if there are old resources waiting on the new Deployments.
+func (r *ReconcileApplication) retireDeployments(a *appv1alpha1.Application, logger logr.Logger) (bool, error) {
+	retired, err := r.retiredDeployments(a)
+	if err != nil {
+		return false, err
+	}
+	orphans, err := r.orphanedReplicaSets(a)
+	if err != nil {
+		return false, err
+	}
+	if len(retired) == 0 && len(orphans) == 0 {
+		return false, nil
+	}
+
+	ready, err := r.deploymentsReady(a)
+	if err != nil {
+		return false, err
+	}
+	if !ready {
+		logger.Info("Waiting for Deployments to become ready before retiring old resources")
+		return true, nil
+	}
+
+	for _, d := range retired {
+		logger.Info("Deleting retired Deployment", "Deleted.Namespace", d.Namespace, "Deleted.Name", d.Name)
+		err = r.client.Delete(context.TODO(), d)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	for _, rs := range orphans {
+		logger.Info("Deleting orphaned ReplicaSet", "Deleted.Namespace", rs.Namespace, "Deleted.Name", rs.Name)
+		err = r.client.Delete(context.TODO(), rs)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// retireConfigMaps deletes the ConfigMaps for earlier configurations of the
+// Application, but only once all the Deployments for the current
+// configuration are ready, as the pods that are being replaced may still
+// need them.
+func (r *ReconcileApplication) retireConfigMaps(a *appv1alpha1.Application, logger logr.Logger) error {
+	ready, err := r.deploymentsReady(a)
+	if err != nil || !ready {
+		return err
+	}
+
+	current := configMapNameForApp(a)
+	configMaps := &corev1.ConfigMapList{}
+	err = r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), configMaps)
+	if err != nil {
+		return err
+	}
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if !metav1.IsControlledBy(cm, a) || cm.Name == current {
+			continue
+		}
+		logger.Info("Deleting retired ConfigMap", "Deleted.Namespace", cm.Namespace, "Deleted.Name", cm.Name)
+		err = r.client.Delete(context.TODO(), cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// retiredDeployments returns the Deployments controlled by the Application
+// that are not part of the current configuration.
+func (r *ReconcileApplication) retiredDeployments(a *appv1alpha1.Application) ([]*appsv1.Deployment, error) {
+	current := map[string]bool{}
+	for _, name := range currentDeploymentNames(a) {
+		current[name] = true
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), deployments)
+	if err != nil {
+		return nil, err
+	}
+	retired := []*appsv1.Deployment{}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if metav1.IsControlledBy(d, a) && !current[d.Name] {
+			retired = append(retired, d)
+		}
+	}
+	return retired, nil
+}
+
+// orphanedReplicaSets returns the ReplicaSets for the Application that were
+// left behind by replaceDeployment, and were not adopted by the replacement.
+//
+// Other ReplicaSets without a controller, for example ones created by hand,
+// are left alone.
+func (r *ReconcileApplication) orphanedReplicaSets(a *appv1alpha1.Application) ([]*appsv1.ReplicaSet, error) {
+	replicaSets := &appsv1.ReplicaSetList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), replicaSets)
+	if err != nil {
+		return nil, err
+	}
+	orphans := []*appsv1.ReplicaSet{}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if _, ok := rs.Annotations[replacedDeploymentAnnotation]; ok && metav1.GetControllerOf(rs) == nil {
+			orphans = append(orphans, rs)
+		}
+	}
+	return orphans, nil
+}
+
+// deploymentsReady returns true if all the Deployments for the current
+// configuration of the Application exist and are ready.
+//
+// For blue/green processes, only the active colour needs to be ready.
+func (r *ReconcileApplication) deploymentsReady(a *appv1alpha1.Application) (bool, error) {
+	names := []string{}
+	for _, d := range deploymentsFromApplication(a) {
+		names = append(names, d.Name)
+	}
+	if name := activeDeploymentName(a); name != "" {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		found := &appsv1.Deployment{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: a.Namespace}, found)
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !deploymentReady(found) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// currentDeploymentNames returns the names of all the Deployments that can
+// exist for the current configuration of the Application.
+func currentDeploymentNames(a *appv1alpha1.Application) []string {
+	names := []string{}
+	for _, d := range deploymentsFromApplication(a) {
+		names = append(names, d.Name)
+	}
+	return append(names, blueGreenDeploymentNames(a)...)
+}
+
+// deploymentReady returns true if the Deployment has finished rolling out the
+// latest version of its template.
+func deploymentReady(d *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas >= replicas &&
+		d.Status.AvailableReplicas >= replicas
+}
@@ -0,0 +1,179 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// ingressNamespaceSelectorEnvVar is a label selector, for example
+// "name=ingress-nginx", for the namespaces with the ingress controller.
+const ingressNamespaceSelectorEnvVar = "INGRESS_NAMESPACE_SELECTOR"
+
+// parseLabelSelector parses a label selector, returning nil if it's empty or
+// invalid.
+func parseLabelSelector(s string) *metav1.LabelSelector {
+	if s == "" {
+		return nil
+	}
+	selector, err := metav1.ParseToLabelSelector(s)
+	if err != nil {
+		return nil
+	}
+	return selector
+}
+
+// networkPoliciesFromApplication makes a NetworkPolicy for each of the
+// processes in the Application, if the Application has a NetworkPolicy.
+func networkPoliciesFromApplication(app *appv1alpha1.Application, ingressNamespaces *metav1.LabelSelector) []*networkingv1.NetworkPolicy {
+	policies := []*networkingv1.NetworkPolicy{}
+	if !app.Spec.NetworkPolicy {
+		return policies
+	}
+	for _, p := range app.Spec.Processes {
+		policies = append(policies, networkPolicyFromProcess(app, p, ingressNamespaces))
+	}
+	return policies
+}
+
+// networkPolicyFromProcess makes a NetworkPolicy for a process.
+//
+// Processes without a port don't accept any connections, processes with a
+// port accept connections to it from anywhere, unless the process restricts
+// its Ingress.  If the process has Egress rules, all other egress is denied.
+//
+// The primary process accepts connections on the port that the Service sends
+// traffic to, even if it doesn't have a port.
+func networkPolicyFromProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec, ingressNamespaces *metav1.LabelSelector) *networkingv1.NetworkPolicy {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: makeProcessObjectMeta(networkPolicyNameForProcess(app, p), app, p),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *makeLabelSelector(app, p),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{},
+		},
+	}
+	ingressPort := p.Port
+	if p.Name == primaryProcess(app).Name {
+		ingressPort = serviceTargetPort(app, p)
+	}
+	if ingressPort != 0 {
+		port := intstr.FromInt(int(ingressPort))
+		protocol := corev1.ProtocolTCP
+		policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocol, Port: &port}},
+			From:  makeIngressPeers(app, p.Ingress, ingressNamespaces),
+		})
+	}
+	if len(p.Egress) > 0 {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = p.Egress
+	}
+	return policy
+}
+
+// makeIngressPeers returns the peers that can connect to a process, or nil if
+// any peer can.
+func makeIngressPeers(app *appv1alpha1.Application, ingress *appv1alpha1.IngressPolicySpec, ingressNamespaces *metav1.LabelSelector) []networkingv1.NetworkPolicyPeer {
+	if ingress == nil {
+		return nil
+	}
+	peers := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{MatchLabels: selectorLabelsForApp(app)}},
+	}
+	if ingress.FromIngressController && ingressNamespaces != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: ingressNamespaces})
+	}
+	for _, name := range ingress.FromApplications {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{nameLabel: name}},
+		})
+	}
+	if ingress.FromNamespaces != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: ingress.FromNamespaces})
+	}
+	return peers
+}
+
+func networkPolicyNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return resourceName(app, p.Name)
+}
+
+// validateNetworkPolicy returns an error if a process accepts connections
+// from the ingress controller, but the operator doesn't know where it is.
+func (r *ReconcileApplication) validateNetworkPolicy(a *appv1alpha1.Application) error {
+	if !a.Spec.NetworkPolicy || r.ingressNamespaces != nil {
+		return nil
+	}
+	for _, p := range a.Spec.Processes {
+		if p.Ingress != nil && p.Ingress.FromIngressController {
+			return fmt.Errorf("process %s accepts connections from the ingress controller, but %s is not configured", p.Name, ingressNamespaceSelectorEnvVar)
+		}
+	}
+	return nil
+}
+
+// reconcileNetworkPolicies creates or updates the NetworkPolicies for the
+// processes, and deletes the NetworkPolicies that are no longer needed.
+func (r *ReconcileApplication) reconcileNetworkPolicies(a *appv1alpha1.Application, logger logr.Logger) error {
+	current := map[string]bool{}
+	for _, np := range networkPoliciesFromApplication(a, r.ingressNamespaces) {
+		current[np.Name] = true
+		err := r.createOrUpdateNetworkPolicy(a, np, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	policies := &networkingv1.NetworkPolicyList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), policies)
+	if err != nil {
+		return err
+	}
+	for i := range policies.Items {
+		np := &policies.Items[i]
+		if !metav1.IsControlledBy(np, a) || current[np.Name] {
+			continue
+		}
+		logger.Info("Deleting NetworkPolicy", "Deleted.Namespace", np.Namespace, "Deleted.Name", np.Name)
+		err = r.client.Delete(context.TODO(), np)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileApplication) createOrUpdateNetworkPolicy(a *appv1alpha1.Application, policy *networkingv1.NetworkPolicy, logger logr.Logger) error {
+	err := controllerutil.SetControllerReference(a, policy, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	found := &networkingv1.NetworkPolicy{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new NetworkPolicy", "Created.Namespace", policy.Namespace, "Created.Name", policy.Name)
+		return r.client.Create(context.TODO(), policy)
+	} else if err != nil {
+		return err
+	}
+
+	logger.Info("Updating existing NetworkPolicy", "Updated.Namespace", policy.Namespace, "Updated.Name", policy.Name)
+	found.Labels = policy.Labels
+	found.Annotations = mergeAnnotations(found.Annotations, policy.Annotations)
+	found.Spec = policy.Spec
+	return r.client.Update(context.TODO(), found)
+}
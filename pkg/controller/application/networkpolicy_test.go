@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestNetworkPolicyFromProcess(t *testing.T) {
+	app := makeTestApplication()
+
+	np := networkPolicyFromProcess(app, testProcess, nil)
+
+	if !reflect.DeepEqual(np.Spec.PodSelector.MatchLabels, testSelector) {
+		t.Fatalf("NetworkPolicy got PodSelector %#v, wanted %#v", np.Spec.PodSelector.MatchLabels, testSelector)
+	}
+	if l := len(np.Spec.Ingress); l != 1 {
+		t.Fatalf("NetworkPolicy got %d ingress rules, wanted 1", l)
+	}
+	rule := np.Spec.Ingress[0]
+	if p := rule.Ports[0].Port.IntValue(); p != int(testProcess.Port) {
+		t.Fatalf("NetworkPolicy got port %d, wanted %d", p, testProcess.Port)
+	}
+	if rule.From != nil {
+		t.Fatalf("NetworkPolicy got From %#v, wanted nil", rule.From)
+	}
+	if !reflect.DeepEqual(np.Spec.PolicyTypes, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}) {
+		t.Fatalf("NetworkPolicy got PolicyTypes %#v", np.Spec.PolicyTypes)
+	}
+}
+
+func TestNetworkPolicyFromProcessWithoutPort(t *testing.T) {
+	process := testProcess
+	process.Name = "worker"
+	process.Port = 0
+	app := makeTestApplication()
+	app.Spec.Processes = append(app.Spec.Processes, process)
+
+	np := networkPolicyFromProcess(app, process, nil)
+
+	if l := len(np.Spec.Ingress); l != 0 {
+		t.Fatalf("NetworkPolicy got %d ingress rules, wanted 0", l)
+	}
+}
+
+func TestNetworkPolicyAllowsServiceTargetPort(t *testing.T) {
+	portTests := []struct {
+		port   int32
+		wanted int
+	}{
+		{8080, 8080},
+		{0, 80},
+	}
+
+	for _, tt := range portTests {
+		app := makeTestApplication()
+		app.Spec.Processes[0].Port = tt.port
+		process := app.Spec.Processes[0]
+
+		np := networkPolicyFromProcess(app, process, nil)
+		svc := serviceFromApplication(app)
+
+		if p := svc.Spec.Ports[0].TargetPort.IntValue(); p != tt.wanted {
+			t.Errorf("port %d: Service got TargetPort %d, wanted %d", tt.port, p, tt.wanted)
+		}
+		if l := len(np.Spec.Ingress); l != 1 {
+			t.Fatalf("port %d: NetworkPolicy got %d ingress rules, wanted 1", tt.port, l)
+		}
+		if p := np.Spec.Ingress[0].Ports[0].Port.IntValue(); p != tt.wanted {
+			t.Errorf("port %d: NetworkPolicy got port %d, wanted %d", tt.port, p, tt.wanted)
+		}
+	}
+}
+
+func TestNetworkPolicyFromProcessWithIngress(t *testing.T) {
+	ingressNamespaces := &metav1.LabelSelector{MatchLabels: map[string]string{"name": "ingress-nginx"}}
+	monitoring := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "monitoring"}}
+	process := testProcess
+	process.Ingress = &appv1alpha1.IngressPolicySpec{
+		FromIngressController: true,
+		FromApplications:      []string{"frontend"},
+		FromNamespaces:        monitoring,
+	}
+
+	np := networkPolicyFromProcess(makeTestApplication(), process, ingressNamespaces)
+
+	wanted := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{nameLabel: testAppName}}},
+		{NamespaceSelector: ingressNamespaces},
+		{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{nameLabel: "frontend"}}},
+		{NamespaceSelector: monitoring},
+	}
+	if from := np.Spec.Ingress[0].From; !reflect.DeepEqual(from, wanted) {
+		t.Fatalf("NetworkPolicy got From %#v, wanted %#v", from, wanted)
+	}
+}
+
+func TestNetworkPolicyFromProcessWithEgress(t *testing.T) {
+	process := testProcess
+	process.Egress = []networkingv1.NetworkPolicyEgressRule{{}}
+
+	np := networkPolicyFromProcess(makeTestApplication(), process, nil)
+
+	wanted := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	if !reflect.DeepEqual(np.Spec.PolicyTypes, wanted) {
+		t.Fatalf("NetworkPolicy got PolicyTypes %#v, wanted %#v", np.Spec.PolicyTypes, wanted)
+	}
+}
+
+func TestReconcileNetworkPolicies(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.NetworkPolicy = true
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	fatalIfError(t, "failed to get networkpolicy", cl.Get(context.TODO(), ns(testAppName+"-web", testNamespace), &networkingv1.NetworkPolicy{}))
+
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	updated.Spec.NetworkPolicy = false
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), updated))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testAppName+"-web", testNamespace), &networkingv1.NetworkPolicy{})
+}
+
+func TestReconcileIngressControllerRequiresSelector(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.NetworkPolicy = true
+	app.Spec.Processes[0].Ingress = &appv1alpha1.IngressPolicySpec{FromIngressController: true}
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+}
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// resourceConflictError is returned when a resource that the Application
+// would create already exists, and isn't managed by the Application.
+type resourceConflictError struct {
+	kind string
+	name string
+}
+
+func (e *resourceConflictError) Error() string {
+	return fmt.Sprintf("%s %s already exists and is not managed by the Application", e.kind, e.name)
+}
+
+// checkControlled returns a resourceConflictError if the existing resource
+// isn't controlled by the Application, so that resources that were created
+// by something else aren't taken over.
+func checkControlled(a *appv1alpha1.Application, kind string, existing metav1.Object) error {
+	if metav1.IsControlledBy(existing, a) {
+		return nil
+	}
+	return &resourceConflictError{kind: kind, name: existing.GetName()}
+}
+
+// stopOnConflict records a resourceConflictError in the Application's status
+// and as an Event, and returns nil so that the Application isn't requeued
+// until it, or the conflicting resource, changes.
+//
+// Other errors are returned unchanged.
+func (r *ReconcileApplication) stopOnConflict(a *appv1alpha1.Application, err error) error {
+	if _, ok := err.(*resourceConflictError); !ok {
+		return err
+	}
+	r.recorder.Event(a, corev1.EventTypeWarning, "ResourceConflict", err.Error())
+	setCondition(&a.Status, appv1alpha1.ApplicationReady, corev1.ConditionFalse, "ResourceConflict", err.Error())
+	return nil
+}
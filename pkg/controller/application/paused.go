@@ -0,0 +1,57 @@
+package application
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// isPaused returns true if the Paused condition was last recorded as true.
+func isPaused(status *appv1alpha1.ApplicationStatus) bool {
+	c := findCondition(status, appv1alpha1.ApplicationPaused)
+	return c != nil && c.Status == corev1.ConditionTrue
+}
+
+// pauseApplication records that the Application's resources are not being
+// reconciled, and reports the changes that have been made to them.
+func (r *ReconcileApplication) pauseApplication(a *appv1alpha1.Application, logger logr.Logger) error {
+	if !isPaused(&a.Status) {
+		logger.Info("Pausing Application")
+	}
+	setCondition(&a.Status, appv1alpha1.ApplicationPaused, corev1.ConditionTrue, "Paused", "the Application's resources are not being reconciled")
+	drift, err := r.detectDrift(a)
+	if err != nil {
+		return err
+	}
+	r.reportDrift(a, drift)
+	return nil
+}
+
+// resumeApplication records the resources that were changed while the
+// Application was paused, before they are reverted.
+//
+// This does nothing if the Application wasn't paused.
+func (r *ReconcileApplication) resumeApplication(a *appv1alpha1.Application, logger logr.Logger) error {
+	if !isPaused(&a.Status) {
+		return nil
+	}
+	drift, err := r.detectDrift(a)
+	if err != nil {
+		return err
+	}
+	drifted := []string{}
+	for _, d := range drift {
+		drifted = append(drifted, d.Kind+"/"+d.Name)
+	}
+	message := "no resources were changed while paused"
+	if len(drifted) > 0 {
+		message = "reverting changes made while paused to " + strings.Join(drifted, ", ")
+	}
+	logger.Info("Resuming Application", "Drifted", drifted)
+	setCondition(&a.Status, appv1alpha1.ApplicationPaused, corev1.ConditionFalse, "Resumed", message)
+	return nil
+}
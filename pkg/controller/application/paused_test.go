@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestPausedApplicationIsNotReconciled(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	setPaused(t, cl, true)
+	scaleDeployment(t, cl, testDeploymentName, 1)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 1)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationPaused, corev1.ConditionTrue)
+	if drift := getApplicationDrift(t, cl); len(drift) != 1 {
+		t.Fatalf("got drift %#v, wanted the paused Deployment", drift)
+	}
+}
+
+func TestResumedApplicationReportsDrift(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	setPaused(t, cl, true)
+	_, err = r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	scaleDeployment(t, cl, testDeploymentName, 1)
+
+	setPaused(t, cl, false)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationPaused, corev1.ConditionFalse)
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	c := findCondition(&app.Status, appv1alpha1.ApplicationPaused)
+	if !strings.Contains(c.Message, "Deployment/"+testDeploymentName) {
+		t.Fatalf("Paused condition message %q doesn't report the drifted Deployment", c.Message)
+	}
+}
+
+func TestDriftedResourcesWithoutChanges(t *testing.T) {
+	r, _ := createApplicationReconciler(t, makeTestApplication())
+	_, err := r.Reconcile(makeRequest())
+	fatalIfError(t, "failed to reconcile", err)
+
+	drifted, err := r.driftedResources(makeTestApplication())
+
+	fatalIfError(t, "failed to find drifted resources", err)
+	if len(drifted) != 0 {
+		t.Fatalf("driftedResources() got %#v, wanted none", drifted)
+	}
+}
+
+func setPaused(t *testing.T, cl client.Client, paused bool) {
+	t.Helper()
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Paused = paused
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+}
+
+func scaleDeployment(t *testing.T, cl client.Client, name string, replicas int32) {
+	t.Helper()
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(name, testNamespace), d))
+	d.Spec.Replicas = &replicas
+	fatalIfError(t, "failed to update deployment", cl.Update(context.TODO(), d))
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// podDisruptionBudgetsFromApplication makes a PodDisruptionBudget for each of
+// the processes in the Application that needs one, scheduled processes don't.
+func podDisruptionBudgetsFromApplication(app *appv1alpha1.Application) []*policyv1beta1.PodDisruptionBudget {
+	budgets := []*policyv1beta1.PodDisruptionBudget{}
+	for _, p := range app.Spec.Processes {
+		if isScheduled(p) {
+			continue
+		}
+		if pdb := podDisruptionBudgetFromProcess(app, p); pdb != nil {
+			budgets = append(budgets, pdb)
+		}
+	}
+	return budgets
+}
+
+// validateDisruptionBudgets returns an error if a process's disruption budget
+// has both MinAvailable and MaxUnavailable, which the API server rejects.
+func validateDisruptionBudgets(a *appv1alpha1.Application) error {
+	for _, p := range a.Spec.Processes {
+		if b := p.DisruptionBudget; b != nil && b.MinAvailable != nil && b.MaxUnavailable != nil {
+			return fmt.Errorf("process %s: disruptionBudget can't have both minAvailable and maxUnavailable", p.Name)
+		}
+	}
+	return nil
+}
+
+// podDisruptionBudgetFromProcess makes a PodDisruptionBudget for a process in
+// the Application, or returns nil if the process doesn't need one.
+//
+// The budget selects all the pods for the process, including canaries and
+// both colours of a blue/green process.
+func podDisruptionBudgetFromProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) *policyv1beta1.PodDisruptionBudget {
+	spec := policyv1beta1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: selectorLabelsForProcess(app, p)},
+	}
+	switch {
+	case p.DisruptionBudget != nil:
+		spec.MinAvailable = p.DisruptionBudget.MinAvailable
+		spec.MaxUnavailable = p.DisruptionBudget.MaxUnavailable
+	case scaledReplicas(app, p) > 1:
+		maxUnavailable := intstr.FromInt(1)
+		spec.MaxUnavailable = &maxUnavailable
+	default:
+		return nil
+	}
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: makeProcessObjectMeta(podDisruptionBudgetNameForProcess(app, p), app, p),
+		Spec:       spec,
+	}
+}
+
+func podDisruptionBudgetNameForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) string {
+	return resourceName(app, p.Name)
+}
+
+// reconcilePodDisruptionBudgets creates or updates the PodDisruptionBudgets
+// for the processes, and deletes any that are no longer needed.
+func (r *ReconcileApplication) reconcilePodDisruptionBudgets(a *appv1alpha1.Application, logger logr.Logger) error {
+	current := map[string]bool{}
+	for _, pdb := range podDisruptionBudgetsFromApplication(a) {
+		current[pdb.Name] = true
+		err := r.createOrUpdatePodDisruptionBudget(a, pdb, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	budgets := &policyv1beta1.PodDisruptionBudgetList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), budgets)
+	if err != nil {
+		return err
+	}
+	for i := range budgets.Items {
+		pdb := &budgets.Items[i]
+		if !metav1.IsControlledBy(pdb, a) || current[pdb.Name] {
+			continue
+		}
+		logger.Info("Deleting PodDisruptionBudget", "Deleted.Namespace", pdb.Namespace, "Deleted.Name", pdb.Name)
+		err = r.client.Delete(context.TODO(), pdb)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// createOrUpdatePodDisruptionBudget creates the PodDisruptionBudget, the spec
+// of an existing PodDisruptionBudget can't be updated, so it's replaced if it
+// has changed.
+func (r *ReconcileApplication) createOrUpdatePodDisruptionBudget(a *appv1alpha1.Application, pdb *policyv1beta1.PodDisruptionBudget, logger logr.Logger) error {
+	err := controllerutil.SetControllerReference(a, pdb, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	found := &policyv1beta1.PodDisruptionBudget{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new PodDisruptionBudget", "Created.Namespace", pdb.Namespace, "Created.Name", pdb.Name)
+		return r.client.Create(context.TODO(), pdb)
+	} else if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(found.Spec, pdb.Spec) {
+		return nil
+	}
+	logger.Info("Replacing existing PodDisruptionBudget", "Updated.Namespace", pdb.Namespace, "Updated.Name", pdb.Name)
+	err = r.client.Delete(context.TODO(), found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return r.client.Create(context.TODO(), pdb)
+}
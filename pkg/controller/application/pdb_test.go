@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestPodDisruptionBudgetFromProcess(t *testing.T) {
+	app := makeTestApplication()
+
+	pdb := podDisruptionBudgetFromProcess(app, testProcess)
+
+	if pdb.Name != testAppName+"-web" {
+		t.Fatalf("PodDisruptionBudget got name %s, wanted %s", pdb.Name, testAppName+"-web")
+	}
+	if !reflect.DeepEqual(pdb.Spec.Selector.MatchLabels, testSelector) {
+		t.Fatalf("PodDisruptionBudget got %#v MatchLabels, wanted %#v", pdb.Spec.Selector.MatchLabels, testSelector)
+	}
+	wanted := intstr.FromInt(1)
+	if !reflect.DeepEqual(pdb.Spec.MaxUnavailable, &wanted) {
+		t.Fatalf("PodDisruptionBudget got MaxUnavailable %#v, wanted %#v", pdb.Spec.MaxUnavailable, &wanted)
+	}
+	if pdb.Spec.MinAvailable != nil {
+		t.Fatalf("PodDisruptionBudget got MinAvailable %#v, wanted nil", pdb.Spec.MinAvailable)
+	}
+}
+
+func TestPodDisruptionBudgetFromProcessWithBudget(t *testing.T) {
+	app := makeTestApplication()
+	minAvailable := intstr.FromString("50%")
+	process := testProcess
+	process.Replicas = 1
+	process.DisruptionBudget = &appv1alpha1.DisruptionBudgetSpec{MinAvailable: &minAvailable}
+
+	pdb := podDisruptionBudgetFromProcess(app, process)
+
+	if !reflect.DeepEqual(pdb.Spec.MinAvailable, &minAvailable) {
+		t.Fatalf("PodDisruptionBudget got MinAvailable %#v, wanted %#v", pdb.Spec.MinAvailable, &minAvailable)
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		t.Fatalf("PodDisruptionBudget got MaxUnavailable %#v, wanted nil", pdb.Spec.MaxUnavailable)
+	}
+}
+
+func TestPodDisruptionBudgetFromProcessWithSingleReplica(t *testing.T) {
+	process := testProcess
+	process.Replicas = 1
+
+	if pdb := podDisruptionBudgetFromProcess(makeTestApplication(), process); pdb != nil {
+		t.Fatalf("PodDisruptionBudget got %#v, wanted nil", pdb)
+	}
+}
+
+func TestReconcileRemovesUnneededPodDisruptionBudgets(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	fatalIfError(t, "failed to get poddisruptionbudget", cl.Get(context.TODO(), ns(testAppName+"-web", testNamespace), pdb))
+
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Processes[0].Replicas = 1
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testAppName+"-web", testNamespace), &policyv1beta1.PodDisruptionBudget{})
+}
+
+func TestReconcileDisruptionBudgetWithBothLimitsIsInvalid(t *testing.T) {
+	app := makeTestApplication()
+	minAvailable, maxUnavailable := intstr.FromInt(1), intstr.FromInt(1)
+	app.Spec.Processes[0].DisruptionBudget = &appv1alpha1.DisruptionBudgetSpec{
+		MinAvailable:   &minAvailable,
+		MaxUnavailable: &maxUnavailable,
+	}
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+	assertNotFound(t, cl, ns(podDisruptionBudgetNameForProcess(app, testProcess), testNamespace), &policyv1beta1.PodDisruptionBudget{})
+}
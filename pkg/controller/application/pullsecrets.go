@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// defaultPullSecretEnvVar is the "namespace/name" of an image pull secret
+// that is copied to every namespace with Applications.
+const defaultPullSecretEnvVar = "DEFAULT_IMAGE_PULL_SECRET"
+
+// parseNamespacedName parses a "namespace/name" string, returning nil if it
+// isn't in that format.
+func parseNamespacedName(s string) *types.NamespacedName {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+}
+
+// appendPullSecret adds a secret to the list of image pull secrets, if it's
+// not already there.
+func appendPullSecret(secrets []corev1.LocalObjectReference, name string) []corev1.LocalObjectReference {
+	for _, s := range secrets {
+		if s.Name == name {
+			return secrets
+		}
+	}
+	return append(secrets, corev1.LocalObjectReference{Name: name})
+}
+
+// reconcileDefaultPullSecret copies the operator's default image pull secret
+// to the Application's namespace, and records it in the status, so that it's
+// added to the Application's pods.
+//
+// ServiceAccounts aren't changed, as the Application may not own them.
+func (r *ReconcileApplication) reconcileDefaultPullSecret(a *appv1alpha1.Application, logger logr.Logger) error {
+	if r.defaultPullSecret == nil {
+		a.Status.ImagePullSecret = ""
+		return nil
+	}
+	err := r.copyDefaultPullSecret(a.Namespace, logger)
+	if err != nil {
+		return err
+	}
+	a.Status.ImagePullSecret = r.defaultPullSecret.Name
+	return nil
+}
+
+// imagePullSecretsForApp returns the Application's image pull secrets, with
+// the copy of the default image pull secret.
+func imagePullSecretsForApp(app *appv1alpha1.Application) []corev1.LocalObjectReference {
+	if app.Status.ImagePullSecret == "" {
+		return app.Spec.ImagePullSecrets
+	}
+	secrets := append([]corev1.LocalObjectReference{}, app.Spec.ImagePullSecrets...)
+	return appendPullSecret(secrets, app.Status.ImagePullSecret)
+}
+
+// copyDefaultPullSecret creates or updates a copy of the default image pull
+// secret in the namespace.
+//
+// The copy is shared by all the Applications in the namespace, so it's not
+// owned by any of them.
+func (r *ReconcileApplication) copyDefaultPullSecret(namespace string, logger logr.Logger) error {
+	if r.defaultPullSecret.Namespace == namespace {
+		return nil
+	}
+	source := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), *r.defaultPullSecret, source)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+			Labels:    map[string]string{managedByLabel: managedBy},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	found := &corev1.Secret{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Copying image pull secret", "Created.Namespace", secret.Namespace, "Created.Name", secret.Name)
+		return r.client.Create(context.TODO(), secret)
+	} else if err != nil {
+		return err
+	}
+	if found.Labels[managedByLabel] != managedBy {
+		logger.Info("Not replacing unmanaged Secret", "Secret.Namespace", found.Namespace, "Secret.Name", found.Name)
+		return nil
+	}
+	if reflect.DeepEqual(found.Data, secret.Data) {
+		return nil
+	}
+	logger.Info("Updating copied image pull secret", "Updated.Namespace", found.Namespace, "Updated.Name", found.Name)
+	found.Data = secret.Data
+	return r.client.Update(context.TODO(), found)
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMakePodSpecWithImagePullSecrets(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+
+	s := makePodSpec(app, testProcess)
+
+	if !reflect.DeepEqual(s.ImagePullSecrets, app.Spec.ImagePullSecrets) {
+		t.Fatalf("makePodSpec() got ImagePullSecrets %#v, wanted %#v", s.ImagePullSecrets, app.Spec.ImagePullSecrets)
+	}
+}
+
+func TestParseNamespacedName(t *testing.T) {
+	parseTests := []struct {
+		s    string
+		want *types.NamespacedName
+	}{
+		{"operators/registry", &types.NamespacedName{Namespace: "operators", Name: "registry"}},
+		{"registry", nil},
+		{"", nil},
+		{"/registry", nil},
+	}
+
+	for _, tt := range parseTests {
+		if n := parseNamespacedName(tt.s); !reflect.DeepEqual(n, tt.want) {
+			t.Errorf("parseNamespacedName(%q) got %#v, wanted %#v", tt.s, n, tt.want)
+		}
+	}
+}
+
+func TestReconcileCopiesDefaultPullSecret(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "operators"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+	}
+	defaultSA := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: testNamespace},
+	}
+	r, cl := createApplicationReconciler(t, makeTestApplication(), source, defaultSA)
+	r.defaultPullSecret = parseNamespacedName("operators/registry")
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	copied := &corev1.Secret{}
+	fatalIfError(t, "failed to get copied secret", cl.Get(context.TODO(), ns("registry", testNamespace), copied))
+	if !reflect.DeepEqual(copied.Data, source.Data) {
+		t.Fatalf("copied Secret got data %#v, wanted %#v", copied.Data, source.Data)
+	}
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	wanted := []corev1.LocalObjectReference{{Name: "registry"}}
+	if secrets := d.Spec.Template.Spec.ImagePullSecrets; !reflect.DeepEqual(secrets, wanted) {
+		t.Fatalf("Deployment got ImagePullSecrets %#v, wanted %#v", secrets, wanted)
+	}
+	sa := &corev1.ServiceAccount{}
+	fatalIfError(t, "failed to get serviceaccount", cl.Get(context.TODO(), ns("default", testNamespace), sa))
+	if len(sa.ImagePullSecrets) != 0 {
+		t.Fatalf("default ServiceAccount got ImagePullSecrets %#v, wanted it unchanged", sa.ImagePullSecrets)
+	}
+}
+
+func TestImagePullSecretsForApp(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	app.Status.ImagePullSecret = "default-registry"
+
+	secrets := imagePullSecretsForApp(app)
+
+	wanted := []corev1.LocalObjectReference{{Name: "registry"}, {Name: "default-registry"}}
+	if !reflect.DeepEqual(secrets, wanted) {
+		t.Fatalf("imagePullSecretsForApp() got %#v, wanted %#v", secrets, wanted)
+	}
+	if l := len(app.Spec.ImagePullSecrets); l != 1 {
+		t.Fatalf("imagePullSecretsForApp() changed the spec's ImagePullSecrets to %#v", app.Spec.ImagePullSecrets)
+	}
+}
+
+func TestReconcileCopiesDefaultPullSecretToEachNamespace(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "operators"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+	}
+	local := makeTestApplication()
+	local.Namespace = source.Namespace
+	other := makeTestApplication()
+	other.Namespace = "other-namespace"
+	r, cl := createApplicationReconciler(t, local, other, source)
+	r.defaultPullSecret = parseNamespacedName("operators/registry")
+
+	for _, a := range []*appv1alpha1.Application{local, other} {
+		_, err := r.Reconcile(reconcile.Request{NamespacedName: ns(a.Name, a.Namespace)})
+		fatalIfError(t, "failed to reconcile", err)
+	}
+
+	copied := &corev1.Secret{}
+	fatalIfError(t, "failed to get copied secret", cl.Get(context.TODO(), ns("registry", other.Namespace), copied))
+	if !reflect.DeepEqual(copied.Data, source.Data) {
+		t.Fatalf("copied Secret got data %#v, wanted %#v", copied.Data, source.Data)
+	}
+	if copied.Type != source.Type {
+		t.Fatalf("copied Secret got type %s, wanted %s", copied.Type, source.Type)
+	}
+	if l := copied.Labels[managedByLabel]; l != managedBy {
+		t.Fatalf("copied Secret got %s label %q, wanted %q", managedByLabel, l, managedBy)
+	}
+	unchanged := &corev1.Secret{}
+	fatalIfError(t, "failed to get source secret", cl.Get(context.TODO(), ns("registry", source.Namespace), unchanged))
+	if len(unchanged.Labels) != 0 {
+		t.Fatalf("source Secret got labels %#v, wanted none", unchanged.Labels)
+	}
+}
@@ -0,0 +1,157 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const releaseComponent = "release"
+
+// releaseProcess returns a process for the release, based on the primary
+// process, so that the release runs with the same configuration.
+//
+// Sidecars are not included, as the Job wouldn't complete while they run.
+func releaseProcess(app *appv1alpha1.Application) appv1alpha1.ProcessSpec {
+	p := primaryProcess(app)
+	p.Name = releaseComponent
+	p.Sidecars = nil
+	if app.Spec.Release.Image != "" {
+		p.Image = app.Spec.Release.Image
+	}
+	return p
+}
+
+// releaseJobFromApplication makes the Job that runs the Application's release
+// command.
+//
+// The name of the Job identifies the image and configuration that it was run
+// for, so a new Job is created when either changes.
+func releaseJobFromApplication(app *appv1alpha1.Application) *batchv1.Job {
+	p := releaseProcess(app)
+	spec := makePodSpec(app, p)
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	spec.Affinity = p.Affinity
+	spec.Containers[0].Command = app.Spec.Release.Command
+	return &batchv1.Job{
+		ObjectMeta: makeProcessObjectMeta(releaseJobNameForApp(app), app, p),
+		Spec: batchv1.JobSpec{
+			BackoffLimit: app.Spec.Release.BackoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: makePodTemplateObjectMeta(app, p),
+				Spec:       spec,
+			},
+		},
+	}
+}
+
+// releaseRevision returns a hash of the release image, command, the images of
+// the processes and the Application's configuration.
+//
+// The process images are included even if the release has its own image, so
+// that the release runs before new images are rolled out.
+func releaseRevision(app *appv1alpha1.Application) string {
+	images := []string{}
+	for _, p := range app.Spec.Processes {
+		images = append(images, p.Image)
+	}
+	parts := []string{releaseProcess(app).Image, configHash(app), hashString(strings.Join(images, "\x00"))}
+	parts = append(parts, app.Spec.Release.Command...)
+	return hashString(strings.Join(parts, "\x00"))
+}
+
+func releaseJobNameForApp(app *appv1alpha1.Application) string {
+	return resourceName(app, releaseComponent, releaseRevision(app))
+}
+
+// reconcileRelease runs the release Job for the current version of the
+// Application.
+//
+// It returns true once the processes can be updated, which is when the Job
+// has succeeded, or if the Application has no release.
+func (r *ReconcileApplication) reconcileRelease(a *appv1alpha1.Application, logger logr.Logger) (bool, error) {
+	if a.Spec.Release == nil {
+		if findCondition(&a.Status, appv1alpha1.ApplicationReleaseFailed) != nil {
+			setCondition(&a.Status, appv1alpha1.ApplicationReleaseFailed, corev1.ConditionFalse, "NoRelease", "")
+		}
+		return true, r.deleteReleaseJobs(a, "", logger)
+	}
+
+	job := releaseJobFromApplication(a)
+	err := controllerutil.SetControllerReference(a, job, r.scheme)
+	if err != nil {
+		return false, err
+	}
+
+	found := &batchv1.Job{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new release Job", "Created.Namespace", job.Namespace, "Created.Name", job.Name)
+		return false, r.client.Create(context.TODO(), job)
+	} else if err != nil {
+		return false, err
+	}
+
+	switch {
+	case jobSucceeded(found):
+		setCondition(&a.Status, appv1alpha1.ApplicationReleaseFailed, corev1.ConditionFalse, "ReleaseSucceeded", "")
+		return true, r.deleteReleaseJobs(a, found.Name, logger)
+	case jobFailed(found):
+		setCondition(&a.Status, appv1alpha1.ApplicationReleaseFailed, corev1.ConditionTrue, "JobFailed",
+			fmt.Sprintf("release Job %s failed", found.Name))
+	}
+	return false, nil
+}
+
+// deleteReleaseJobs deletes the Application's release Jobs, except for the
+// Job with the name to keep.
+func (r *ReconcileApplication) deleteReleaseJobs(a *appv1alpha1.Application, keep string, logger logr.Logger) error {
+	labels := selectorLabelsForApp(a)
+	labels[componentLabel] = releaseComponent
+	jobs := &batchv1.JobList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(labels), jobs)
+	if err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !metav1.IsControlledBy(job, a) || job.Name == keep {
+			continue
+		}
+		logger.Info("Deleting release Job", "Deleted.Namespace", job.Namespace, "Deleted.Name", job.Name)
+		err = r.client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func jobSucceeded(job *batchv1.Job) bool {
+	return jobHasCondition(job, batchv1.JobComplete)
+}
+
+func jobFailed(job *batchv1.Job) bool {
+	return jobHasCondition(job, batchv1.JobFailed)
+}
+
+func jobHasCondition(job *batchv1.Job, t batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == t && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
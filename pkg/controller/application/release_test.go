@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var testReleaseCommand = []string{"rake", "db:migrate"}
+
+func TestReleaseJobFromApplication(t *testing.T) {
+	app := makeReleaseApplication()
+
+	job := releaseJobFromApplication(app)
+
+	spec := job.Spec.Template.Spec
+	if spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Fatalf("release Job got RestartPolicy %s, wanted %s", spec.RestartPolicy, corev1.RestartPolicyNever)
+	}
+	c := spec.Containers[0]
+	if c.Name != testAppName+"-release" {
+		t.Fatalf("release Job got container name %s, wanted %s", c.Name, testAppName+"-release")
+	}
+	if c.Image != testImage {
+		t.Fatalf("release Job got image %s, wanted %s", c.Image, testImage)
+	}
+	if !reflect.DeepEqual(c.Command, testReleaseCommand) {
+		t.Fatalf("release Job got command %#v, wanted %#v", c.Command, testReleaseCommand)
+	}
+	if !reflect.DeepEqual(c.Env, makeEnvFromApp(app)) {
+		t.Fatalf("release Job got env %#v, wanted %#v", c.Env, makeEnvFromApp(app))
+	}
+}
+
+func TestReleaseJobNameChanges(t *testing.T) {
+	changes := []struct {
+		name   string
+		change func(*appv1alpha1.Application)
+	}{
+		{"image", func(a *appv1alpha1.Application) { a.Spec.Processes[0].Image = "test-image:v2" }},
+		{"release image", func(a *appv1alpha1.Application) { a.Spec.Release.Image = "migrations:v2" }},
+		{"environment", func(a *appv1alpha1.Application) { a.Spec.Environment = map[string]string{"new": "value"} }},
+	}
+
+	for _, tt := range changes {
+		app := makeReleaseApplication()
+		before := releaseJobNameForApp(app)
+		tt.change(app)
+
+		if after := releaseJobNameForApp(app); before == after {
+			t.Errorf("%s change: release Job name %s didn't change", tt.name, after)
+		}
+	}
+}
+
+func TestReconcileWaitsForRelease(t *testing.T) {
+	app := makeReleaseApplication()
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationReleaseFailed, corev1.ConditionFalse)
+}
+
+func TestReconcileRunsReleaseForNewImageWithReleaseImage(t *testing.T) {
+	app := makeReleaseApplication()
+	app.Spec.Release.Image = "migrations:v1"
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	released := releaseJobNameForApp(app)
+	markJobFinished(t, cl, released, batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Processes[0].Image = "test-image:v2"
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	if name := releaseJobNameForApp(app); name == released {
+		t.Fatalf("release Job name %s didn't change with the image", name)
+	}
+	fatalIfError(t, "failed to get new release job", cl.Get(context.TODO(), ns(releaseJobNameForApp(app), testNamespace), &batchv1.Job{}))
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	if image := d.Spec.Template.Spec.Containers[0].Image; image != testImage {
+		t.Fatalf("Deployment got image %s before the release, wanted %s", image, testImage)
+	}
+}
+
+func TestReconcileReleaseFailed(t *testing.T) {
+	app := makeReleaseApplication()
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobFailed)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationReleaseFailed, corev1.ConditionTrue)
+}
+
+func TestReconcileKeepsConfigurationUntilReleased(t *testing.T) {
+	app := makeReleaseApplication()
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+	markDeploymentReady(t, cl, testDeploymentName)
+	released := configMapNameForApp(app)
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Environment = map[string]string{"TEST_MODE": "false"}
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	cm := &corev1.ConfigMap{}
+	fatalIfError(t, "failed to get released configmap", cl.Get(context.TODO(), ns(released, testNamespace), cm))
+	if !reflect.DeepEqual(cm.Data, testEnvironment) {
+		t.Fatalf("released ConfigMap got data %#v, wanted %#v", cm.Data, testEnvironment)
+	}
+	assertDeploymentConfigMap(t, cl, released)
+
+	markJobFinished(t, cl, releaseJobNameForApp(app), batchv1.JobComplete)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfigMap(t, cl, configMapNameForApp(app))
+
+	markDeploymentReady(t, cl, testDeploymentName)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(released, testNamespace), &corev1.ConfigMap{})
+	assertConfigMapHasData(t, testAppName, testNamespace, cl, app.Spec.Environment)
+}
+
+func assertDeploymentConfigMap(t *testing.T, cl client.Client, name string) {
+	t.Helper()
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	ref := d.Spec.Template.Spec.Containers[0].Env[0].ValueFrom.ConfigMapKeyRef
+	if ref.Name != name {
+		t.Fatalf("Deployment got ConfigMap %s, wanted %s", ref.Name, name)
+	}
+}
+
+func makeReleaseApplication() *appv1alpha1.Application {
+	app := makeTestApplication()
+	app.Spec.Release = &appv1alpha1.ReleaseSpec{Command: testReleaseCommand}
+	return app
+}
+
+func markJobFinished(t *testing.T, cl client.Client, name string, ct batchv1.JobConditionType) {
+	t.Helper()
+	job := &batchv1.Job{}
+	fatalIfError(t, "failed to get job", cl.Get(context.TODO(), ns(name, testNamespace), job))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: ct, Status: corev1.ConditionTrue}}
+	fatalIfError(t, "failed to update job", cl.Update(context.TODO(), job))
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// progressDeadlineExceeded is the reason the Deployment controller gives in
+// the Progressing condition when a rollout has exceeded its deadline.
+const progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// updateRolloutStatus sets the RolloutStuck condition on the Application from
+// the state of the Deployments for its processes.
+func (r *ReconcileApplication) updateRolloutStatus(a *appv1alpha1.Application) error {
+	stuck := []string{}
+	for _, name := range currentDeploymentNames(a) {
+		found := &appsv1.Deployment{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: a.Namespace}, found)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if rolloutStuck(found) {
+			stuck = append(stuck, found.Name)
+		}
+	}
+
+	if len(stuck) == 0 {
+		setCondition(&a.Status, appv1alpha1.ApplicationRolloutStuck, corev1.ConditionFalse, "RolloutProgressing", "")
+		return nil
+	}
+	setCondition(&a.Status, appv1alpha1.ApplicationRolloutStuck, corev1.ConditionTrue, progressDeadlineExceeded,
+		fmt.Sprintf("Deployments exceeded their progress deadline: %s", strings.Join(stuck, ", ")))
+	return nil
+}
+
+// rolloutStuck returns true if the Deployment controller has given up waiting
+// for the Deployment to make progress.
+func rolloutStuck(d *appsv1.Deployment) bool {
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == progressDeadlineExceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// updateReadyStatus sets the Ready condition on the Application, it's ready
+// when all the Deployments for the current configuration are ready.
+func (r *ReconcileApplication) updateReadyStatus(a *appv1alpha1.Application) error {
+	ready, err := r.deploymentsReady(a)
+	if err != nil {
+		return err
+	}
+	if !ready {
+		setCondition(&a.Status, appv1alpha1.ApplicationReady, corev1.ConditionFalse, "DeploymentsNotReady", "")
+		return nil
+	}
+	setCondition(&a.Status, appv1alpha1.ApplicationReady, corev1.ConditionTrue, "DeploymentsReady", "")
+	return nil
+}
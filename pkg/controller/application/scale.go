@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// mirroredReplicasAnnotation is the replicas of the primary process when the
+// Application's Replicas were last set from them.
+const mirroredReplicasAnnotation = "app.bigkevmcd.com/mirrored-replicas"
+
+// scaledReplicas returns the replicas for the process, the Application's
+// Replicas replace the primary process's replicas when they're set,
+// otherwise the replicas of the scaling schedule that updateScalingStatus
+// last recorded as active are used.
+func scaledReplicas(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) int32 {
+	active := findScalingSchedule(p, activeScalingScheduleName(&app.Status, p.Name))
+	return replicasForSchedule(app, p, active)
+}
+
+// replicasForSchedule returns the replicas for the process while the scaling
+// schedule is active, which can be nil if none are.
+func replicasForSchedule(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec, active *appv1alpha1.ScalingSchedule) int32 {
+	if app.Spec.Replicas != nil && p.Name == primaryProcess(app).Name {
+		return *app.Spec.Replicas
+	}
+	if active != nil {
+		return active.Replicas
+	}
+	return p.Replicas
+}
+
+// mirrorReplicas sets the Application's Replicas, which the scale subresource
+// reports, to the replicas of the primary process if they're not set, or if
+// the primary process's replicas have changed since they were last mirrored.
+//
+// When a scaling schedule for the primary process starts, its replicas
+// replace the Application's Replicas, until it's scaled again.
+//
+// The primary process's replicas are recorded in an annotation, in the same
+// update as the Application's Replicas.
+func (r *ReconcileApplication) mirrorReplicas(a *appv1alpha1.Application, now time.Time, logger logr.Logger) error {
+	p := primaryProcess(a)
+	active, _ := activeScalingSchedule(p, now)
+	processReplicas := strconv.Itoa(int(p.Replicas))
+	mirrored, ok := a.Annotations[mirroredReplicasAnnotation]
+
+	replicas := a.Spec.Replicas
+	switch {
+	case active != nil && (replicas == nil || scalingScheduleName(active) != activeScalingScheduleName(&a.Status, p.Name)):
+		v := active.Replicas
+		replicas = &v
+	case replicas == nil || (ok && mirrored != processReplicas && active == nil):
+		v := p.Replicas
+		replicas = &v
+	}
+	if mirrored == processReplicas && reflect.DeepEqual(replicas, a.Spec.Replicas) {
+		return nil
+	}
+	if !reflect.DeepEqual(replicas, a.Spec.Replicas) {
+		logger.Info("Setting replicas", "Process", p.Name, "Replicas", *replicas)
+	}
+	a.Spec.Replicas = replicas
+	if a.Annotations == nil {
+		a.Annotations = map[string]string{}
+	}
+	a.Annotations[mirroredReplicasAnnotation] = processReplicas
+	return r.client.Update(context.TODO(), a)
+}
+
+// updateScaleStatus records the number of pods for the primary process, and
+// the selector for them, for the scale subresource.
+//
+// The pods for canaries and both colours of a blue/green process are
+// included.
+func (r *ReconcileApplication) updateScaleStatus(a *appv1alpha1.Application) error {
+	selector := selectorLabelsForProcess(a, primaryProcess(a))
+	deployments := &appsv1.DeploymentList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selector), deployments)
+	if err != nil {
+		return err
+	}
+	replicas := int32(0)
+	for i := range deployments.Items {
+		if metav1.IsControlledBy(&deployments.Items[i], a) {
+			replicas += deployments.Items[i].Status.Replicas
+		}
+	}
+	a.Status.Replicas = replicas
+	a.Status.Selector = metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: selector})
+	return nil
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestScaledReplicas(t *testing.T) {
+	worker := appv1alpha1.ProcessSpec{Name: "worker", Image: testImage, Replicas: 2}
+	three := int32(3)
+	scaleTests := []struct {
+		replicas *int32
+		process  appv1alpha1.ProcessSpec
+		wanted   int32
+	}{
+		{nil, testProcess, testReplicas},
+		{&three, testProcess, 3},
+		{&three, worker, 2},
+	}
+
+	for _, tt := range scaleTests {
+		app := makeTestApplication()
+		app.Spec.Processes = append(app.Spec.Processes, worker)
+		app.Spec.Replicas = tt.replicas
+		if r := scaledReplicas(app, tt.process); r != tt.wanted {
+			t.Errorf("scaledReplicas(%s) got %d, wanted %d", tt.process.Name, r, tt.wanted)
+		}
+	}
+}
+
+func TestScaleApplication(t *testing.T) {
+	app := makeTestApplication()
+	replicas := int32(2)
+	app.Spec.Replicas = &replicas
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 2)
+
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	d.Status.Replicas = 2
+	fatalIfError(t, "failed to update deployment", cl.Update(context.TODO(), d))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	if updated.Status.Replicas != 2 {
+		t.Fatalf("got status replicas %d, wanted 2", updated.Status.Replicas)
+	}
+	selector := metav1.FormatLabelSelector(makeLabelSelector(app, testProcess))
+	if updated.Status.Selector != selector {
+		t.Fatalf("got status selector %q, wanted %q", updated.Status.Selector, selector)
+	}
+}
+
+func TestReconcileMirrorsReplicas(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, testReplicas, 0)
+
+	d := &appsv1.Deployment{}
+	fatalIfError(t, "failed to get deployment", cl.Get(context.TODO(), ns(testDeploymentName, testNamespace), d))
+	d.Status.Replicas = testReplicas
+	fatalIfError(t, "failed to update deployment", cl.Update(context.TODO(), d))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, testReplicas, testReplicas)
+}
+
+func TestReconcileMirrorsScalingScheduleReplicas(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].ScalingSchedules = []appv1alpha1.ScalingSchedule{
+		{Schedule: "0 0 1 1 *", Replicas: 3},
+	}
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, 3, 0)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 3)
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	replicas := int32(4)
+	app.Spec.Replicas = &replicas
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, 4, 0)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 4)
+}
+
+func TestReconcileMirrorsChangedProcessReplicas(t *testing.T) {
+	r, cl := createApplicationReconciler(t, makeTestApplication())
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	scaled := int32(7)
+	app.Spec.Replicas = &scaled
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, 7, 0)
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.Processes[0].Replicas = 3
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertScale(t, cl, 3, 0)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 3)
+}
+
+// assertScale checks the fields that the scale subresource reads.
+func assertScale(t *testing.T, cl client.Client, specReplicas, statusReplicas int32) {
+	t.Helper()
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	if app.Spec.Replicas == nil || *app.Spec.Replicas != specReplicas {
+		t.Fatalf("got spec replicas %v, wanted %d", app.Spec.Replicas, specReplicas)
+	}
+	if app.Status.Replicas != statusReplicas {
+		t.Fatalf("got status replicas %d, wanted %d", app.Status.Replicas, statusReplicas)
+	}
+}
@@ -0,0 +1,137 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// activeScalingSchedule returns the scaling schedule for the process that
+// started most recently at now, or nil if none have started, and when the
+// next schedule starts, or the zero time if none will.
+//
+// Schedules that can't be parsed are ignored, they're rejected by
+// validateScalingSchedules.
+func activeScalingSchedule(p appv1alpha1.ProcessSpec, now time.Time) (*appv1alpha1.ScalingSchedule, time.Time) {
+	var active *appv1alpha1.ScalingSchedule
+	var started, next time.Time
+	for i := range p.ScalingSchedules {
+		s := &p.ScalingSchedules[i]
+		c, loc, err := parseScalingSchedule(*s)
+		if err != nil {
+			continue
+		}
+		if at, ok := c.prev(now.In(loc)); ok && (active == nil || at.After(started)) {
+			active, started = s, at
+		}
+		if at, ok := c.next(now.In(loc)); ok && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return active, next
+}
+
+func parseScalingSchedule(s appv1alpha1.ScalingSchedule) (*cronSchedule, *time.Location, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid time zone %q: %s", s.TimeZone, err)
+	}
+	c, err := parseCron(s.Schedule)
+	return c, loc, err
+}
+
+func scalingScheduleName(s *appv1alpha1.ScalingSchedule) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Schedule
+}
+
+// activeScalingScheduleName returns the name of the scaling schedule that was
+// last recorded as active for the process.
+func activeScalingScheduleName(status *appv1alpha1.ApplicationStatus, process string) string {
+	for _, s := range status.Scaling {
+		if s.Process == process {
+			return s.Schedule
+		}
+	}
+	return ""
+}
+
+// findScalingSchedule returns the process's scaling schedule with the name,
+// or nil if there isn't one.
+func findScalingSchedule(p appv1alpha1.ProcessSpec, name string) *appv1alpha1.ScalingSchedule {
+	if name == "" {
+		return nil
+	}
+	for i := range p.ScalingSchedules {
+		if scalingScheduleName(&p.ScalingSchedules[i]) == name {
+			return &p.ScalingSchedules[i]
+		}
+	}
+	return nil
+}
+
+// validateScalingSchedules returns an error if a scaling schedule can't be
+// parsed, or will never start.
+func validateScalingSchedules(a *appv1alpha1.Application) error {
+	for _, p := range a.Spec.Processes {
+		if len(p.ScalingSchedules) > 0 && isScheduled(p) {
+			return fmt.Errorf("process %s runs on a schedule and can't have scaling schedules", p.Name)
+		}
+		for _, s := range p.ScalingSchedules {
+			c, loc, err := parseScalingSchedule(s)
+			if err != nil {
+				return fmt.Errorf("process %s: %s", p.Name, err)
+			}
+			if _, ok := c.next(time.Now().In(loc)); !ok {
+				return fmt.Errorf("process %s: scaling schedule %q never starts", p.Name, s.Schedule)
+			}
+		}
+	}
+	return nil
+}
+
+// updateScalingStatus records the active scaling schedules of the processes
+// in the status, these set the replicas that scaledReplicas returns until the
+// status is next updated.
+//
+// It returns how long it is until the next schedule starts, or zero.
+func updateScalingStatus(a *appv1alpha1.Application, now time.Time) time.Duration {
+	var statuses []appv1alpha1.ScalingStatus
+	var delay time.Duration
+	for _, p := range a.Spec.Processes {
+		if len(p.ScalingSchedules) == 0 {
+			continue
+		}
+		active, next := activeScalingSchedule(p, now)
+		status := appv1alpha1.ScalingStatus{Process: p.Name, Replicas: replicasForSchedule(a, p, active)}
+		if active != nil {
+			status.Schedule = scalingScheduleName(active)
+		}
+		if !next.IsZero() {
+			nextChange := metav1.NewTime(next)
+			status.NextChange = &nextChange
+			if d := next.Sub(now); delay == 0 || d < delay {
+				delay = d
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	a.Status.Scaling = statuses
+	return delay
+}
+
+// shortestDelay returns the shortest of the delays that isn't zero, or zero.
+func shortestDelay(delays ...time.Duration) time.Duration {
+	var shortest time.Duration
+	for _, d := range delays {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return shortest
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var testScalingSchedules = []appv1alpha1.ScalingSchedule{
+	{Name: "day", Schedule: "0 8 * * 1-5", Replicas: 10, TimeZone: "Europe/London"},
+	{Name: "night", Schedule: "0 20 * * *", Replicas: 2, TimeZone: "Europe/London"},
+}
+
+func TestActiveScalingSchedule(t *testing.T) {
+	process := testProcess
+	process.ScalingSchedules = testScalingSchedules
+	london, err := time.LoadLocation("Europe/London")
+	fatalIfError(t, "failed to load time zone", err)
+	scheduleTests := []struct {
+		now        time.Time
+		wanted     string
+		wantedNext time.Time
+	}{
+		// Wednesday
+		{time.Date(2019, time.October, 16, 12, 0, 0, 0, london), "day", time.Date(2019, time.October, 16, 20, 0, 0, 0, london)},
+		{time.Date(2019, time.October, 16, 21, 0, 0, 0, london), "night", time.Date(2019, time.October, 17, 8, 0, 0, 0, london)},
+		// Saturday
+		{time.Date(2019, time.October, 19, 12, 0, 0, 0, london), "night", time.Date(2019, time.October, 19, 20, 0, 0, 0, london)},
+		// The schedules are in London time.
+		{time.Date(2019, time.October, 16, 7, 30, 0, 0, time.UTC), "day", time.Date(2019, time.October, 16, 20, 0, 0, 0, london)},
+	}
+
+	for _, tt := range scheduleTests {
+		active, next := activeScalingSchedule(process, tt.now)
+		if active == nil || active.Name != tt.wanted {
+			t.Errorf("activeScalingSchedule(%s) got %#v, wanted %s", tt.now, active, tt.wanted)
+		}
+		if !next.Equal(tt.wantedNext) {
+			t.Errorf("activeScalingSchedule(%s) got next %s, wanted %s", tt.now, next, tt.wantedNext)
+		}
+	}
+}
+
+func TestScaledReplicasFromScalingStatus(t *testing.T) {
+	worker := appv1alpha1.ProcessSpec{Name: "worker", Image: testImage, Replicas: 1, ScalingSchedules: testScalingSchedules}
+	london, err := time.LoadLocation("Europe/London")
+	fatalIfError(t, "failed to load time zone", err)
+	replicaTests := []struct {
+		now    time.Time
+		wanted int32
+	}{
+		{time.Date(2019, time.October, 16, 12, 0, 0, 0, london), 10},
+		{time.Date(2019, time.October, 16, 21, 0, 0, 0, london), 2},
+	}
+
+	for _, tt := range replicaTests {
+		app := makeTestApplication()
+		app.Spec.Processes = append(app.Spec.Processes, worker)
+		if r := scaledReplicas(app, worker); r != worker.Replicas {
+			t.Errorf("scaledReplicas() before the status is updated got %d, wanted %d", r, worker.Replicas)
+		}
+
+		updateScalingStatus(app, tt.now)
+
+		if r := scaledReplicas(app, worker); r != tt.wanted {
+			t.Errorf("scaledReplicas() at %s got %d, wanted %d", tt.now, r, tt.wanted)
+		}
+	}
+}
+
+func TestUpdateScalingStatus(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].ScalingSchedules = []appv1alpha1.ScalingSchedule{
+		{Schedule: "0 8 * * *", Replicas: 10},
+	}
+	now := time.Date(2019, time.October, 16, 7, 30, 0, 0, time.UTC)
+
+	delay := updateScalingStatus(app, now)
+
+	if delay != 30*time.Minute {
+		t.Fatalf("updateScalingStatus() got delay %s, wanted %s", delay, 30*time.Minute)
+	}
+	status := app.Status.Scaling[0]
+	if status.Process != testProcess.Name || status.Schedule != "0 8 * * *" {
+		t.Fatalf("got scaling status %#v", status)
+	}
+	if !status.NextChange.Time.Equal(now.Add(30 * time.Minute)) {
+		t.Fatalf("got next change %s, wanted %s", status.NextChange, now.Add(30*time.Minute))
+	}
+}
+
+func TestReconcileScalingSchedules(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].ScalingSchedules = []appv1alpha1.ScalingSchedule{
+		{Schedule: "* * * * *", Replicas: 3},
+	}
+	r, cl := createApplicationReconciler(t, app)
+
+	res, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 3)
+	if res.RequeueAfter <= 0 || res.RequeueAfter > time.Minute {
+		t.Fatalf("got RequeueAfter %s, wanted the next minute", res.RequeueAfter)
+	}
+}
+
+func TestInvalidScalingSchedule(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].ScalingSchedules = []appv1alpha1.ScalingSchedule{
+		{Schedule: "0 8 * * *", Replicas: 3, TimeZone: "Nowhere/Unknown"},
+	}
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+}
+
+func TestScalingSchedulesAreNotDrift(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].ScalingSchedules = []appv1alpha1.ScalingSchedule{
+		{Schedule: "* * * * *", Replicas: 3},
+	}
+	r, cl := createApplicationReconciler(t, app)
+	_, err := r.Reconcile(makeRequest())
+	fatalIfError(t, "failed to reconcile", err)
+	scaleDeployment(t, cl, testDeploymentName, testReplicas)
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+
+	drift, err := r.detectDrift(app)
+
+	fatalIfError(t, "failed to detect drift", err)
+	if len(drift) != 0 {
+		t.Fatalf("got drift %#v, wanted none", drift)
+	}
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	seccompAnnotation     = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompRuntimeDefault = "runtime/default"
+)
+
+// makePodSecurityContext returns the security context for the process's
+// pods, by default the containers must not run as root.
+func makePodSecurityContext(p appv1alpha1.ProcessSpec) *corev1.PodSecurityContext {
+	if p.SecurityContext != nil {
+		return p.SecurityContext
+	}
+	runAsNonRoot := true
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+	}
+}
+
+// makeContainerSecurityContext returns the security context for the
+// process's container, by default the root filesystem is read-only, and all
+// capabilities are dropped.
+func makeContainerSecurityContext(p appv1alpha1.ProcessSpec) *corev1.SecurityContext {
+	if p.ContainerSecurityContext != nil {
+		return p.ContainerSecurityContext
+	}
+	return defaultContainerSecurityContext()
+}
+
+// defaultContainerSecurityContext returns the hardened security context for
+// containers that don't provide their own.
+func defaultContainerSecurityContext() *corev1.SecurityContext {
+	readOnlyRootFilesystem := true
+	allowPrivilegeEscalation := false
+	return &corev1.SecurityContext{
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
+
+// addSeccompProfile adds the runtime's default seccomp profile to the pod
+// annotations, unless a profile has been provided in the Application's
+// annotations.
+func addSeccompProfile(annotations map[string]string) map[string]string {
+	if _, ok := annotations[seccompAnnotation]; ok {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[seccompAnnotation] = seccompRuntimeDefault
+	return annotations
+}
+
+// validateSecurity returns an error if a process requests a privileged
+// container, including its sidecars and init containers, in a namespace that
+// doesn't allow them.
+func (r *ReconcileApplication) validateSecurity(a *appv1alpha1.Application) error {
+	if r.privilegedNamespaces[a.Namespace] {
+		return nil
+	}
+	for _, p := range a.Spec.Processes {
+		contexts := []*corev1.SecurityContext{p.ContainerSecurityContext}
+		for _, c := range additionalContainers(p) {
+			contexts = append(contexts, c.SecurityContext)
+		}
+		for _, sc := range contexts {
+			if sc != nil && sc.Privileged != nil && *sc.Privileged {
+				return fmt.Errorf("process %s requests a privileged container, which is not allowed in namespace %s", p.Name, a.Namespace)
+			}
+		}
+	}
+	return nil
+}
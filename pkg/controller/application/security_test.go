@@ -0,0 +1,93 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMakePodSecurityContext(t *testing.T) {
+	runAsNonRoot := true
+
+	sc := makePodSecurityContext(testProcess)
+
+	wanted := &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}
+	if !reflect.DeepEqual(sc, wanted) {
+		t.Fatalf("makePodSecurityContext() got %#v, wanted %#v", sc, wanted)
+	}
+}
+
+func TestMakeContainerSecurityContext(t *testing.T) {
+	readOnly := true
+	allowEscalation := false
+
+	sc := makeContainerSecurityContext(testProcess)
+
+	wanted := &corev1.SecurityContext{
+		ReadOnlyRootFilesystem:   &readOnly,
+		AllowPrivilegeEscalation: &allowEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	if !reflect.DeepEqual(sc, wanted) {
+		t.Fatalf("makeContainerSecurityContext() got %#v, wanted %#v", sc, wanted)
+	}
+}
+
+func TestSecurityContextOverrides(t *testing.T) {
+	runAsUser := int64(1000)
+	readOnly := false
+	process := testProcess
+	process.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &runAsUser}
+	process.ContainerSecurityContext = &corev1.SecurityContext{ReadOnlyRootFilesystem: &readOnly}
+
+	s := makePodSpec(makeTestApplication(), process)
+
+	if !reflect.DeepEqual(s.SecurityContext, process.SecurityContext) {
+		t.Fatalf("makePodSpec() got SecurityContext %#v, wanted %#v", s.SecurityContext, process.SecurityContext)
+	}
+	if sc := s.Containers[0].SecurityContext; !reflect.DeepEqual(sc, process.ContainerSecurityContext) {
+		t.Fatalf("makePodSpec() got container SecurityContext %#v, wanted %#v", sc, process.ContainerSecurityContext)
+	}
+}
+
+func TestPodTemplateSeccompProfile(t *testing.T) {
+	app := makeTestApplication()
+
+	meta := makePodTemplateObjectMeta(app, testProcess)
+
+	if v := meta.Annotations[seccompAnnotation]; v != "runtime/default" {
+		t.Fatalf("got seccomp profile %q, wanted %q", v, "runtime/default")
+	}
+
+	app.Annotations = map[string]string{seccompAnnotation: "unconfined"}
+	meta = makePodTemplateObjectMeta(app, testProcess)
+
+	if v := meta.Annotations[seccompAnnotation]; v != "unconfined" {
+		t.Fatalf("got seccomp profile %q, wanted %q", v, "unconfined")
+	}
+}
+
+func TestReconcileRefusesPrivilegedContainers(t *testing.T) {
+	privileged := true
+	app := makeTestApplication()
+	app.Spec.Processes[0].ContainerSecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+
+	r.privilegedNamespaces = parseNamespaces("default, " + testNamespace)
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionFalse)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+}
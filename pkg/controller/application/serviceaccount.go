@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// serviceAccountFromApplication makes a ServiceAccount for the Application,
+// or returns nil if the Application doesn't need its own ServiceAccount.
+func serviceAccountFromApplication(app *appv1alpha1.Application) *corev1.ServiceAccount {
+	spec := app.Spec.ServiceAccount
+	if spec == nil || spec.Name != "" {
+		return nil
+	}
+	meta := makeObjectMeta(ownedServiceAccountNameForApp(app), app)
+	meta.Annotations = mergeAnnotations(meta.Annotations, spec.Annotations)
+	return &corev1.ServiceAccount{
+		ObjectMeta:       meta,
+		ImagePullSecrets: spec.ImagePullSecrets,
+	}
+}
+
+// serviceAccountNameForApp returns the name of the ServiceAccount for the
+// Application's pods, or an empty string to use the namespace default.
+func serviceAccountNameForApp(app *appv1alpha1.Application) string {
+	spec := app.Spec.ServiceAccount
+	if spec == nil {
+		return ""
+	}
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return ownedServiceAccountNameForApp(app)
+}
+
+func ownedServiceAccountNameForApp(app *appv1alpha1.Application) string {
+	return resourceName(app)
+}
+
+// reconcileServiceAccount creates or updates the ServiceAccount for the
+// Application, or deletes it if the Application no longer needs it.
+//
+// An existing ServiceAccount with the same name that isn't controlled by the
+// Application is left alone, and a resourceConflictError is returned.
+func (r *ReconcileApplication) reconcileServiceAccount(a *appv1alpha1.Application, logger logr.Logger) error {
+	sa := serviceAccountFromApplication(a)
+	if sa == nil {
+		return r.deleteOwnedServiceAccount(a, logger)
+	}
+	err := controllerutil.SetControllerReference(a, sa, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	found := &corev1.ServiceAccount{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new ServiceAccount", "Created.Namespace", sa.Namespace, "Created.Name", sa.Name)
+		return r.client.Create(context.TODO(), sa)
+	} else if err != nil {
+		return err
+	}
+	err = checkControlled(a, "ServiceAccount", found)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Updating existing ServiceAccount", "Updated.Namespace", sa.Namespace, "Updated.Name", sa.Name)
+	found.Labels = sa.Labels
+	found.Annotations = mergeAnnotations(found.Annotations, sa.Annotations)
+	found.ImagePullSecrets = sa.ImagePullSecrets
+	return r.client.Update(context.TODO(), found)
+}
+
+func (r *ReconcileApplication) deleteOwnedServiceAccount(a *appv1alpha1.Application, logger logr.Logger) error {
+	found := &corev1.ServiceAccount{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: ownedServiceAccountNameForApp(a), Namespace: a.Namespace}, found)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(found, a) {
+		return nil
+	}
+	logger.Info("Deleting ServiceAccount", "Deleted.Namespace", found.Namespace, "Deleted.Name", found.Name)
+	err = r.client.Delete(context.TODO(), found)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
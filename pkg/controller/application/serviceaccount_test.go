@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestServiceAccountFromApplication(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.ServiceAccount = &appv1alpha1.ServiceAccountSpec{
+		Annotations:      map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/test"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+
+	sa := serviceAccountFromApplication(app)
+
+	if sa.Name != testAppName {
+		t.Fatalf("ServiceAccount got name %s, wanted %s", sa.Name, testAppName)
+	}
+	if !reflect.DeepEqual(sa.Annotations, app.Spec.ServiceAccount.Annotations) {
+		t.Fatalf("ServiceAccount got annotations %#v, wanted %#v", sa.Annotations, app.Spec.ServiceAccount.Annotations)
+	}
+	if !reflect.DeepEqual(sa.ImagePullSecrets, app.Spec.ServiceAccount.ImagePullSecrets) {
+		t.Fatalf("ServiceAccount got ImagePullSecrets %#v, wanted %#v", sa.ImagePullSecrets, app.Spec.ServiceAccount.ImagePullSecrets)
+	}
+}
+
+func TestServiceAccountNameForApp(t *testing.T) {
+	nameTests := []struct {
+		spec *appv1alpha1.ServiceAccountSpec
+		want string
+	}{
+		{nil, ""},
+		{&appv1alpha1.ServiceAccountSpec{}, testAppName},
+		{&appv1alpha1.ServiceAccountSpec{Name: "existing"}, "existing"},
+	}
+
+	for _, tt := range nameTests {
+		app := makeTestApplication()
+		app.Spec.ServiceAccount = tt.spec
+
+		if n := serviceAccountNameForApp(app); n != tt.want {
+			t.Errorf("serviceAccountNameForApp(%#v) got %q, wanted %q", tt.spec, n, tt.want)
+		}
+		if n := makePodSpec(app, testProcess).ServiceAccountName; n != tt.want {
+			t.Errorf("makePodSpec() with %#v got ServiceAccountName %q, wanted %q", tt.spec, n, tt.want)
+		}
+	}
+}
+
+func TestReconcileServiceAccount(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.ServiceAccount = &appv1alpha1.ServiceAccountSpec{}
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	fatalIfError(t, "failed to get serviceaccount", cl.Get(context.TODO(), ns(testAppName, testNamespace), &corev1.ServiceAccount{}))
+
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), app))
+	app.Spec.ServiceAccount.Name = "existing"
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertNotFound(t, cl, ns(testAppName, testNamespace), &corev1.ServiceAccount{})
+}
+
+func TestReconcileServiceAccountNotControlledByApplication(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.ServiceAccount = &appv1alpha1.ServiceAccountSpec{}
+	existing := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: testAppName, Namespace: testNamespace},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "existing-secret"}},
+	}
+	r, cl := createApplicationReconciler(t, app, existing)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	sa := &corev1.ServiceAccount{}
+	fatalIfError(t, "failed to get serviceaccount", cl.Get(context.TODO(), ns(testAppName, testNamespace), sa))
+	if !reflect.DeepEqual(sa.ImagePullSecrets, existing.ImagePullSecrets) {
+		t.Fatalf("ServiceAccount got ImagePullSecrets %#v, wanted %#v", sa.ImagePullSecrets, existing.ImagePullSecrets)
+	}
+	if len(sa.OwnerReferences) != 0 {
+		t.Fatalf("ServiceAccount got OwnerReferences %#v, wanted none", sa.OwnerReferences)
+	}
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationReady, corev1.ConditionFalse)
+	assertEvent(t, recorder, "ResourceConflict")
+	assertNotFound(t, cl, ns(testDeploymentName, testNamespace), &appsv1.Deployment{})
+}
@@ -0,0 +1,40 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// replicasForProcess returns the number of pods to run for the process, this
+// is zero while the Application is suspended.
+func replicasForProcess(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) int32 {
+	if app.Spec.Suspend {
+		return 0
+	}
+	return p.Replicas
+}
+
+// updateSuspendedStatus records whether or not the Application is suspended,
+// and the replicas that the processes will be scaled back to.
+//
+// The condition is only recorded once the Application has been suspended.
+func updateSuspendedStatus(a *appv1alpha1.Application) {
+	if !a.Spec.Suspend {
+		if findCondition(&a.Status, appv1alpha1.ApplicationSuspended) != nil {
+			setCondition(&a.Status, appv1alpha1.ApplicationSuspended, corev1.ConditionFalse, "Resumed", "")
+		}
+		return
+	}
+	replicas := []string{}
+	for _, p := range a.Spec.Processes {
+		if !isScheduled(p) {
+			replicas = append(replicas, fmt.Sprintf("%s=%d", p.Name, scaledReplicas(a, p)))
+		}
+	}
+	setCondition(&a.Status, appv1alpha1.ApplicationSuspended, corev1.ConditionTrue, "Suspended",
+		"the processes are scaled to zero, and will resume with "+strings.Join(replicas, ", "))
+}
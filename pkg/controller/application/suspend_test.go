@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestProcessScaledToZero(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Processes[0].Replicas = 0
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 0)
+}
+
+func TestSuspendApplication(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Suspend = true
+	app.Spec.Processes = append(app.Spec.Processes, testClockProcess)
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+
+	_, err := r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, 0)
+	cj := &batchv1beta1.CronJob{}
+	fatalIfError(t, "failed to get cronjob", cl.Get(context.TODO(), ns(testAppName+"-clock", testNamespace), cj))
+	if !*cj.Spec.Suspend {
+		t.Fatal("CronJob is not suspended")
+	}
+	fatalIfError(t, "failed to get service", cl.Get(context.TODO(), ns(testAppName, testNamespace), &corev1.Service{}))
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationSuspended, corev1.ConditionTrue)
+
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	updated.Spec.Suspend = false
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), updated))
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertDeploymentConfiguration(t, testDeploymentName, testNamespace, cl, testReplicas)
+	fatalIfError(t, "failed to get cronjob", cl.Get(context.TODO(), ns(testAppName+"-clock", testNamespace), cj))
+	if *cj.Spec.Suspend {
+		t.Fatal("CronJob is still suspended")
+	}
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationSuspended, corev1.ConditionFalse)
+}
@@ -0,0 +1,226 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// makeVolumes returns the volumes for the process's pods, only the volumes
+// that the process or its additional containers mount are included, along
+// with the volumes for the Application's files and addons.
+func makeVolumes(app *appv1alpha1.Application, p appv1alpha1.ProcessSpec) []corev1.Volume {
+	mounted := map[string]bool{}
+	for _, m := range p.VolumeMounts {
+		mounted[m.Name] = true
+	}
+	for _, c := range additionalContainers(p) {
+		for _, m := range c.VolumeMounts {
+			mounted[m.Name] = true
+		}
+	}
+	var volumes []corev1.Volume
+	if v := makeFilesVolume(app); v != nil {
+		volumes = append(volumes, *v)
+	}
+	volumes = append(volumes, makeAddonVolumes(app)...)
+	for _, v := range app.Spec.Volumes {
+		if !mounted[v.Name] {
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name:         v.Name,
+			VolumeSource: makeVolumeSource(app, v),
+		})
+	}
+	return volumes
+}
+
+// makeVolumeSource returns the source for the volume, validateVolumes ensures
+// that exactly one source is set.
+func makeVolumeSource(app *appv1alpha1.Application, v appv1alpha1.VolumeSpec) corev1.VolumeSource {
+	switch {
+	case v.PersistentVolumeClaim != nil:
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: persistentVolumeClaimNameForVolume(app, v),
+			},
+		}
+	case v.ConfigMap != nil:
+		return corev1.VolumeSource{ConfigMap: v.ConfigMap}
+	case v.Secret != nil:
+		return corev1.VolumeSource{Secret: v.Secret}
+	case v.EmptyDir != nil:
+		return corev1.VolumeSource{EmptyDir: v.EmptyDir}
+	}
+	return corev1.VolumeSource{}
+}
+
+// validateVolumes returns an error if a volume doesn't have exactly one
+// source.
+func validateVolumes(a *appv1alpha1.Application) error {
+	for _, v := range a.Spec.Volumes {
+		sources := 0
+		for _, set := range []bool{v.EmptyDir != nil, v.ConfigMap != nil, v.Secret != nil, v.PersistentVolumeClaim != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("volume %s must have exactly one of emptyDir, configMap, secret or persistentVolumeClaim", v.Name)
+		}
+	}
+	return nil
+}
+
+// persistentVolumeClaimsFromApplication makes a PersistentVolumeClaim for
+// each of the Application's persistent volumes.
+func persistentVolumeClaimsFromApplication(app *appv1alpha1.Application) []*corev1.PersistentVolumeClaim {
+	claims := []*corev1.PersistentVolumeClaim{}
+	for _, v := range app.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims = append(claims, persistentVolumeClaimFromVolume(app, v))
+		}
+	}
+	return claims
+}
+
+func persistentVolumeClaimFromVolume(app *appv1alpha1.Application, v appv1alpha1.VolumeSpec) *corev1.PersistentVolumeClaim {
+	spec := v.PersistentVolumeClaim
+	accessModes := spec.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: makeObjectMeta(persistentVolumeClaimNameForVolume(app, v), app),
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: spec.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: spec.Size,
+				},
+			},
+		},
+	}
+}
+
+func persistentVolumeClaimNameForVolume(app *appv1alpha1.Application, v appv1alpha1.VolumeSpec) string {
+	return resourceName(app, v.Name)
+}
+
+// retainVolume returns true if the claim for the volume should be kept when
+// the Application is deleted.
+func retainVolume(v appv1alpha1.VolumeSpec) bool {
+	return v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.RetentionPolicy == appv1alpha1.VolumeRetain
+}
+
+// reconcilePersistentVolumeClaims creates the claims for the Application's
+// persistent volumes, and deletes the claims for volumes that have been
+// removed, unless they are retained.
+//
+// Claims that are deleted with the Application are owned by it, retained
+// claims are only labelled, so that the garbage collector ignores them.
+func (r *ReconcileApplication) reconcilePersistentVolumeClaims(a *appv1alpha1.Application, logger logr.Logger) error {
+	current := map[string]bool{}
+	for _, v := range a.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc := persistentVolumeClaimFromVolume(a, v)
+		current[pvc.Name] = true
+		err := r.createOrUpdatePersistentVolumeClaim(a, pvc, retainVolume(v), logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	claims := &corev1.PersistentVolumeClaimList{}
+	err := r.client.List(context.TODO(), client.InNamespace(a.Namespace).MatchingLabels(selectorLabelsForApp(a)), claims)
+	if err != nil {
+		return err
+	}
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if !metav1.IsControlledBy(pvc, a) || current[pvc.Name] {
+			continue
+		}
+		logger.Info("Deleting PersistentVolumeClaim", "Deleted.Namespace", pvc.Namespace, "Deleted.Name", pvc.Name)
+		err = r.client.Delete(context.TODO(), pvc)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// createOrUpdatePersistentVolumeClaim creates the claim, most of the spec of
+// an existing claim can't be changed, so only the requested size is updated
+// to allow volumes to be expanded.
+//
+// An existing claim that wasn't created for the Application is left alone,
+// and a resourceConflictError is returned.
+func (r *ReconcileApplication) createOrUpdatePersistentVolumeClaim(a *appv1alpha1.Application, pvc *corev1.PersistentVolumeClaim, retain bool, logger logr.Logger) error {
+	if !retain {
+		err := controllerutil.SetControllerReference(a, pvc, r.scheme)
+		if err != nil {
+			return err
+		}
+	}
+
+	found := &corev1.PersistentVolumeClaim{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new PersistentVolumeClaim", "Created.Namespace", pvc.Namespace, "Created.Name", pvc.Name)
+		return r.client.Create(context.TODO(), pvc)
+	} else if err != nil {
+		return err
+	}
+	if !claimManagedBy(found, a) {
+		return &resourceConflictError{kind: "PersistentVolumeClaim", name: found.Name}
+	}
+
+	logger.Info("Updating existing PersistentVolumeClaim", "Updated.Namespace", pvc.Namespace, "Updated.Name", pvc.Name)
+	found.Labels = pvc.Labels
+	found.OwnerReferences = removeOwnerReferences(found.OwnerReferences, a)
+	found.OwnerReferences = append(found.OwnerReferences, pvc.OwnerReferences...)
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requested.Cmp(found.Spec.Resources.Requests[corev1.ResourceStorage]) > 0 {
+		found.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+	}
+	return r.client.Update(context.TODO(), found)
+}
+
+// claimManagedBy returns true if the claim is controlled by the Application,
+// or is a retained claim for the Application, retained claims aren't owned,
+// and are recognised by their labels.
+func claimManagedBy(pvc *corev1.PersistentVolumeClaim, a *appv1alpha1.Application) bool {
+	if metav1.IsControlledBy(pvc, a) {
+		return true
+	}
+	return metav1.GetControllerOf(pvc) == nil &&
+		pvc.Labels[managedByLabel] == managedBy &&
+		pvc.Labels[instanceLabel] == a.Name
+}
+
+// removeOwnerReferences returns the owner references that don't refer to the
+// Application.
+func removeOwnerReferences(refs []metav1.OwnerReference, a *appv1alpha1.Application) []metav1.OwnerReference {
+	kept := []metav1.OwnerReference{}
+	for _, ref := range refs {
+		if ref.UID != a.UID {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
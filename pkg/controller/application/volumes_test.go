@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMakeVolumes(t *testing.T) {
+	app := makeVolumesApplication(appv1alpha1.VolumeDelete)
+	process := testProcess
+	process.VolumeMounts = []corev1.VolumeMount{
+		{Name: "data", MountPath: "/data"},
+		{Name: "tmp", MountPath: "/tmp"},
+	}
+
+	s := makePodSpec(app, process)
+
+	wanted := []corev1.Volume{
+		{
+			Name: "tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: testAppName + "-data"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(s.Volumes, wanted) {
+		t.Fatalf("makePodSpec() got Volumes %#v, wanted %#v", s.Volumes, wanted)
+	}
+	if m := s.Containers[0].VolumeMounts; !reflect.DeepEqual(m, process.VolumeMounts) {
+		t.Fatalf("makePodSpec() got VolumeMounts %#v, wanted %#v", m, process.VolumeMounts)
+	}
+}
+
+func TestMakeVolumesOnlyIncludesMountedVolumes(t *testing.T) {
+	app := makeVolumesApplication(appv1alpha1.VolumeDelete)
+
+	if v := makeVolumes(app, testProcess); v != nil {
+		t.Fatalf("makeVolumes() got %#v, wanted nil", v)
+	}
+}
+
+func TestPersistentVolumeClaimsFromApplication(t *testing.T) {
+	app := makeVolumesApplication(appv1alpha1.VolumeDelete)
+
+	claims := persistentVolumeClaimsFromApplication(app)
+
+	if l := len(claims); l != 1 {
+		t.Fatalf("persistentVolumeClaimsFromApplication() got %d claims, wanted 1", l)
+	}
+	pvc := claims[0]
+	if pvc.Name != testAppName+"-data" {
+		t.Fatalf("PersistentVolumeClaim got name %s, wanted %s", pvc.Name, testAppName+"-data")
+	}
+	if *pvc.Spec.StorageClassName != "fast" {
+		t.Fatalf("PersistentVolumeClaim got storage class %s, wanted fast", *pvc.Spec.StorageClassName)
+	}
+	wantedModes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	if !reflect.DeepEqual(pvc.Spec.AccessModes, wantedModes) {
+		t.Fatalf("PersistentVolumeClaim got access modes %#v, wanted %#v", pvc.Spec.AccessModes, wantedModes)
+	}
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.String() != "10Gi" {
+		t.Fatalf("PersistentVolumeClaim got size %s, wanted 10Gi", size.String())
+	}
+}
+
+func TestReconcilePersistentVolumeClaims(t *testing.T) {
+	retentionTests := []struct {
+		policy appv1alpha1.VolumeRetentionPolicy
+		owned  bool
+	}{
+		{appv1alpha1.VolumeDelete, true},
+		{appv1alpha1.VolumeRetain, false},
+	}
+
+	for _, tt := range retentionTests {
+		app := makeVolumesApplication(tt.policy)
+		r, cl := createApplicationReconciler(t, app)
+
+		_, err := r.Reconcile(makeRequest())
+
+		fatalIfError(t, "failed to reconcile", err)
+		pvc := &corev1.PersistentVolumeClaim{}
+		fatalIfError(t, "failed to get persistentvolumeclaim", cl.Get(context.TODO(), ns(testAppName+"-data", testNamespace), pvc))
+		if owned := metav1.IsControlledBy(pvc, app); owned != tt.owned {
+			t.Errorf("%s PersistentVolumeClaim got owned %v, wanted %v", tt.policy, owned, tt.owned)
+		}
+	}
+}
+
+func makeVolumesApplication(policy appv1alpha1.VolumeRetentionPolicy) *appv1alpha1.Application {
+	storageClass := "fast"
+	app := makeTestApplication()
+	app.Spec.Volumes = []appv1alpha1.VolumeSpec{
+		{
+			Name:     "tmp",
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+		{
+			Name: "data",
+			PersistentVolumeClaim: &appv1alpha1.PersistentVolumeClaimSpec{
+				Size:             resource.MustParse("10Gi"),
+				StorageClassName: &storageClass,
+				RetentionPolicy:  policy,
+			},
+		},
+	}
+	return app
+}
+
+func TestReconcilePersistentVolumeClaimNotCreatedForApplication(t *testing.T) {
+	app := makeVolumesApplication(appv1alpha1.VolumeDelete)
+	existing := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: testAppName + "-data", Namespace: testNamespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	r, cl := createApplicationReconciler(t, app, existing)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	pvc := &corev1.PersistentVolumeClaim{}
+	fatalIfError(t, "failed to get persistentvolumeclaim", cl.Get(context.TODO(), ns(existing.Name, testNamespace), pvc))
+	if len(pvc.OwnerReferences) != 0 {
+		t.Fatalf("PersistentVolumeClaim got OwnerReferences %#v, wanted none", pvc.OwnerReferences)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "1Gi" {
+		t.Fatalf("PersistentVolumeClaim got size %s, wanted 1Gi", size.String())
+	}
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationReady, corev1.ConditionFalse)
+}
+
+func TestReconcileRetainedPersistentVolumeClaimIsReused(t *testing.T) {
+	app := makeVolumesApplication(appv1alpha1.VolumeRetain)
+	r, cl := createApplicationReconciler(t, app)
+	req := makeRequest()
+	_, err := r.Reconcile(req)
+	fatalIfError(t, "failed to reconcile", err)
+
+	_, err = r.Reconcile(req)
+
+	fatalIfError(t, "failed to reconcile", err)
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), ns(testAppName, testNamespace), updated))
+	if c := findCondition(&updated.Status, appv1alpha1.ApplicationReady); c != nil && c.Reason == "ResourceConflict" {
+		t.Fatalf("retained PersistentVolumeClaim reported as a conflict: %s", c.Message)
+	}
+}
+
+func TestValidateVolumes(t *testing.T) {
+	volumeTests := []struct {
+		name   string
+		volume appv1alpha1.VolumeSpec
+		valid  bool
+	}{
+		{"one source", appv1alpha1.VolumeSpec{Name: "tmp", EmptyDir: &corev1.EmptyDirVolumeSource{}}, true},
+		{"no source", appv1alpha1.VolumeSpec{Name: "tmp"}, false},
+		{"several sources", appv1alpha1.VolumeSpec{
+			Name:      "tmp",
+			EmptyDir:  &corev1.EmptyDirVolumeSource{},
+			ConfigMap: &corev1.ConfigMapVolumeSource{},
+		}, false},
+	}
+
+	for _, tt := range volumeTests {
+		app := makeTestApplication()
+		app.Spec.Volumes = []appv1alpha1.VolumeSpec{tt.volume}
+		if err := validateVolumes(app); (err == nil) != tt.valid {
+			t.Errorf("%s: validateVolumes() got error %v, wanted valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestReconcileVolumeWithoutSourceIsInvalid(t *testing.T) {
+	app := makeTestApplication()
+	app.Spec.Volumes = []appv1alpha1.VolumeSpec{{Name: "tmp"}}
+	r, cl := createApplicationReconciler(t, app)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	assertApplicationCondition(t, cl, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue)
+}
@@ -0,0 +1,183 @@
+package applicationtemplate
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+	"github.com/bigkevmcd/applications/pkg/controller/application"
+)
+
+var log = logf.Log.WithName("controller_applicationtemplate")
+
+// Add creates a new ApplicationTemplate Controller and adds it to the Manager.
+//
+// ApplicationTemplates are cluster-scoped, and generate Applications in any
+// namespace, so the controller is only started if the operator is watching
+// all namespaces.
+func Add(mgr manager.Manager) error {
+	if ns := os.Getenv(k8sutil.WatchNamespaceEnvVar); ns != "" {
+		log.Info("Not watching ApplicationTemplates, the operator only watches one namespace", "Namespace", ns)
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler.
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileApplicationTemplate{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler.
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New("applicationtemplate-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &appv1alpha1.ApplicationTemplate{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &appv1alpha1.Application{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appv1alpha1.ApplicationTemplate{},
+	})
+}
+
+// ReconcileApplicationTemplate reconciles an ApplicationTemplate object.
+type ReconcileApplicationTemplate struct {
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile generates the Applications for an ApplicationTemplate, and
+// deletes the Applications for targets that have been removed.
+func (r *ReconcileApplicationTemplate) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Name", request.Name)
+	reqLogger.Info("Reconciling ApplicationTemplate")
+
+	template := &appv1alpha1.ApplicationTemplate{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, template)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status := template.Status.DeepCopy()
+	err = r.reconcileTemplate(template, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if equality.Semantic.DeepEqual(&template.Status, status) {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, r.client.Status().Update(context.TODO(), template)
+}
+
+func (r *ReconcileApplicationTemplate) reconcileTemplate(t *appv1alpha1.ApplicationTemplate, logger logr.Logger) error {
+	apps, err := applicationsFromTemplate(t)
+	if err != nil {
+		logger.Info("ApplicationTemplate is invalid", "Reason", err.Error())
+		application.SetCondition(&t.Status.Conditions, appv1alpha1.ApplicationInvalid, corev1.ConditionTrue, "ValidationFailed", err.Error())
+		return nil
+	}
+	application.SetCondition(&t.Status.Conditions, appv1alpha1.ApplicationInvalid, corev1.ConditionFalse, "Validated", "")
+
+	current := map[string]bool{}
+	for _, app := range apps {
+		name := app.Namespace + "/" + app.Name
+		current[name] = true
+		err := r.createOrUpdateApplication(t, app, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing := &appv1alpha1.ApplicationList{}
+	err = r.client.List(context.TODO(), client.MatchingLabels(map[string]string{templateLabel: t.Name}), existing)
+	if err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		app := &existing.Items[i]
+		if !metav1.IsControlledBy(app, t) || current[app.Namespace+"/"+app.Name] {
+			continue
+		}
+		logger.Info("Deleting Application", "Deleted.Namespace", app.Namespace, "Deleted.Name", app.Name)
+		err = r.client.Delete(context.TODO(), app)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	t.Status.Applications = []string{}
+	for name := range current {
+		t.Status.Applications = append(t.Status.Applications, name)
+	}
+	sort.Strings(t.Status.Applications)
+	return nil
+}
+
+// createOrUpdateApplication creates the Application, or merges the spec into
+// the existing Application, the Application's status is left alone.
+func (r *ReconcileApplicationTemplate) createOrUpdateApplication(t *appv1alpha1.ApplicationTemplate, app *appv1alpha1.Application, logger logr.Logger) error {
+	err := controllerutil.SetControllerReference(t, app, r.scheme)
+	if err != nil {
+		return err
+	}
+	desired := app.Spec.DeepCopy()
+	err = setAppliedSpec(app, desired)
+	if err != nil {
+		return err
+	}
+
+	found := &appv1alpha1.Application{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: app.Name, Namespace: app.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new Application", "Created.Namespace", app.Namespace, "Created.Name", app.Name)
+		return r.client.Create(context.TODO(), app)
+	} else if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(found, t) {
+		logger.Info("Not updating Application that isn't owned by the template", "Namespace", found.Namespace, "Name", found.Name)
+		return nil
+	}
+	spec := mergeSpec(&found.Spec, desired, appliedSpec(found))
+	if equality.Semantic.DeepEqual(found.Spec, spec) && equality.Semantic.DeepEqual(found.Labels, app.Labels) &&
+		found.Annotations[application.TemplateSpecAnnotation] == app.Annotations[application.TemplateSpecAnnotation] {
+		return nil
+	}
+	logger.Info("Updating existing Application", "Updated.Namespace", app.Namespace, "Updated.Name", app.Name)
+	found.Labels = app.Labels
+	found.Spec = spec
+	err = setAppliedSpec(found, desired)
+	if err != nil {
+		return err
+	}
+	return r.client.Update(context.TODO(), found)
+}
@@ -0,0 +1,99 @@
+package applicationtemplate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var _ reconcile.Reconciler = &ReconcileApplicationTemplate{}
+
+func TestReconcileCreatesApplications(t *testing.T) {
+	r, cl := createTemplateReconciler(t, makeTestTemplate())
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	for _, namespace := range []string{"dev", "prod"} {
+		app := &appv1alpha1.Application{}
+		fatalIfError(t, "failed to get application", cl.Get(context.TODO(), types.NamespacedName{Name: testTemplateName, Namespace: namespace}, app))
+	}
+	template := &appv1alpha1.ApplicationTemplate{}
+	fatalIfError(t, "failed to get template", cl.Get(context.TODO(), types.NamespacedName{Name: testTemplateName}, template))
+	wanted := []string{"dev/" + testTemplateName, "prod/" + testTemplateName}
+	if !reflect.DeepEqual(template.Status.Applications, wanted) {
+		t.Fatalf("got Applications %#v, wanted %#v", template.Status.Applications, wanted)
+	}
+}
+
+func TestReconcileDeletesRemovedTargets(t *testing.T) {
+	r, cl := createTemplateReconciler(t, makeTestTemplate())
+	_, err := r.Reconcile(makeRequest())
+	fatalIfError(t, "failed to reconcile", err)
+
+	template := &appv1alpha1.ApplicationTemplate{}
+	fatalIfError(t, "failed to get template", cl.Get(context.TODO(), types.NamespacedName{Name: testTemplateName}, template))
+	template.Spec.Targets = template.Spec.Targets[1:]
+	fatalIfError(t, "failed to update template", cl.Update(context.TODO(), template))
+	_, err = r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	err = cl.Get(context.TODO(), types.NamespacedName{Name: testTemplateName, Namespace: "dev"}, &appv1alpha1.Application{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("got error %v, wanted not found", err)
+	}
+}
+
+func TestReconcileKeepsChangesFromOtherControllers(t *testing.T) {
+	r, cl := createTemplateReconciler(t, makeTestTemplate())
+	_, err := r.Reconcile(makeRequest())
+	fatalIfError(t, "failed to reconcile", err)
+	name := types.NamespacedName{Name: testTemplateName, Namespace: "dev"}
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), name, app))
+	replicas := int32(3)
+	app.Spec.Replicas = &replicas
+	app.Spec.Processes[0].Image = "example/web:v3"
+	fatalIfError(t, "failed to update application", cl.Update(context.TODO(), app))
+
+	_, err = r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	updated := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), name, updated))
+	if !reflect.DeepEqual(updated.Spec, app.Spec) {
+		t.Fatalf("got spec %#v, wanted %#v", updated.Spec, app.Spec)
+	}
+}
+
+func createTemplateReconciler(t *testing.T, obj ...runtime.Object) (ReconcileApplicationTemplate, client.Client) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Kubernetes types to scheme: %s", err)
+	}
+	if err := appv1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Application types to scheme: %s", err)
+	}
+	cl := fake.NewFakeClientWithScheme(s, obj...)
+	return ReconcileApplicationTemplate{client: cl, scheme: s}, cl
+}
+
+func makeRequest() reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: testTemplateName}}
+}
+
+func fatalIfError(t *testing.T, msg string, err error) {
+	if err != nil {
+		t.Fatalf("%s: %s", msg, err)
+	}
+}
@@ -0,0 +1,112 @@
+package applicationtemplate
+
+import (
+	"encoding/json"
+	"reflect"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+	"github.com/bigkevmcd/applications/pkg/controller/application"
+)
+
+// mergeSpec returns the spec for an existing Application generated from the
+// template.
+//
+// This is the template's spec, except for the fields that other controllers
+// change:
+//
+//   - the replicas, which are changed through the scale subresource, these
+//     are reset if the template changes the primary process's replicas, so
+//     that they're set from the new value
+//   - the images of the processes, and the environment, which Promotions change
+//   - the canaries of the processes, which are removed when they are promoted
+//     or aborted
+//
+// These keep their existing values, unless the template has changed them
+// since it last applied them.  If there's no record of the spec that the
+// template last applied, all of the spec is replaced.
+func mergeSpec(existing, desired, applied *appv1alpha1.ApplicationSpec) appv1alpha1.ApplicationSpec {
+	spec := *desired.DeepCopy()
+	if applied == nil {
+		return spec
+	}
+	if reflect.DeepEqual(desired.Replicas, applied.Replicas) && !primaryReplicasChanged(desired, applied) {
+		spec.Replicas = existing.Replicas
+	}
+	for i := range spec.Processes {
+		p := &spec.Processes[i]
+		current := findProcess(existing, p.Name)
+		previous := findProcess(applied, p.Name)
+		if current == nil || previous == nil {
+			continue
+		}
+		if p.Image == previous.Image {
+			p.Image = current.Image
+		}
+		if reflect.DeepEqual(p.Canary, previous.Canary) {
+			p.Canary = current.Canary.DeepCopy()
+		}
+	}
+	spec.Environment = mergeEnvironment(existing.Environment, desired.Environment, applied.Environment)
+	return spec
+}
+
+// primaryReplicasChanged returns true if the template has changed the
+// replicas of the primary process since it last applied them.
+func primaryReplicasChanged(desired, applied *appv1alpha1.ApplicationSpec) bool {
+	if len(desired.Processes) == 0 || len(applied.Processes) == 0 {
+		return false
+	}
+	current, previous := desired.Processes[0], applied.Processes[0]
+	return current.Name != previous.Name || current.Replicas != previous.Replicas
+}
+
+// mergeEnvironment returns the existing environment, with the variables that
+// the template has added, changed or removed since it last applied them.
+func mergeEnvironment(existing, desired, applied map[string]string) map[string]string {
+	if existing == nil && desired == nil {
+		return nil
+	}
+	env := map[string]string{}
+	for k, v := range existing {
+		env[k] = v
+	}
+	for k, v := range desired {
+		if previous, ok := applied[k]; !ok || previous != v {
+			env[k] = v
+		}
+	}
+	for k := range applied {
+		if _, ok := desired[k]; !ok {
+			delete(env, k)
+		}
+	}
+	return env
+}
+
+// appliedSpec returns the spec that the template last applied to the
+// Application, or nil if it isn't known.
+func appliedSpec(app *appv1alpha1.Application) *appv1alpha1.ApplicationSpec {
+	s, ok := app.Annotations[application.TemplateSpecAnnotation]
+	if !ok {
+		return nil
+	}
+	spec := &appv1alpha1.ApplicationSpec{}
+	if err := json.Unmarshal([]byte(s), spec); err != nil {
+		return nil
+	}
+	return spec
+}
+
+// setAppliedSpec records the spec that the template applied to the
+// Application.
+func setAppliedSpec(app *appv1alpha1.Application, spec *appv1alpha1.ApplicationSpec) error {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[application.TemplateSpecAnnotation] = string(b)
+	return nil
+}
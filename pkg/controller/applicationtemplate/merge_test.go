@@ -0,0 +1,136 @@
+package applicationtemplate
+
+import (
+	"reflect"
+	"testing"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+func TestMergeSpec(t *testing.T) {
+	scaled := int32(4)
+	canary := &appv1alpha1.CanarySpec{Image: "example/web:v2", Weight: 10, Action: appv1alpha1.CanaryPromote}
+	base := appv1alpha1.ApplicationSpec{
+		Environment: map[string]string{"LOG_LEVEL": "debug"},
+		Processes: []appv1alpha1.ProcessSpec{
+			{Name: "web", Image: "example/web:v1", Replicas: 1, Canary: canary},
+		},
+	}
+	mergeTests := []struct {
+		name     string
+		existing func(*appv1alpha1.ApplicationSpec)
+		template func(*appv1alpha1.ApplicationSpec)
+		wanted   func(*appv1alpha1.ApplicationSpec)
+	}{
+		{
+			"promoted image is kept",
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Image = "example/web:v3" },
+			func(s *appv1alpha1.ApplicationSpec) {},
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Image = "example/web:v3" },
+		},
+		{
+			"changed template image is applied",
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Image = "example/web:v3" },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Image = "example/web:v4" },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Image = "example/web:v4" },
+		},
+		{
+			"completed canary isn't restored",
+			func(s *appv1alpha1.ApplicationSpec) {
+				s.Processes[0].Image = canary.Image
+				s.Processes[0].Canary = nil
+			},
+			func(s *appv1alpha1.ApplicationSpec) {},
+			func(s *appv1alpha1.ApplicationSpec) {
+				s.Processes[0].Image = canary.Image
+				s.Processes[0].Canary = nil
+			},
+		},
+		{
+			"scaled replicas are kept",
+			func(s *appv1alpha1.ApplicationSpec) { s.Replicas = &scaled },
+			func(s *appv1alpha1.ApplicationSpec) {},
+			func(s *appv1alpha1.ApplicationSpec) { s.Replicas = &scaled },
+		},
+		{
+			"scaled replicas are reset when the primary process's replicas change",
+			func(s *appv1alpha1.ApplicationSpec) { s.Replicas = &scaled },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Replicas = 2 },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Replicas = 2 },
+		},
+		{
+			"promoted environment is kept",
+			func(s *appv1alpha1.ApplicationSpec) {
+				s.Environment = map[string]string{"LOG_LEVEL": "info", "API": "v2"}
+			},
+			func(s *appv1alpha1.ApplicationSpec) {},
+			func(s *appv1alpha1.ApplicationSpec) {
+				s.Environment = map[string]string{"LOG_LEVEL": "info", "API": "v2"}
+			},
+		},
+		{
+			"changed template environment is applied",
+			func(s *appv1alpha1.ApplicationSpec) {
+				s.Environment = map[string]string{"LOG_LEVEL": "info", "API": "v2"}
+			},
+			func(s *appv1alpha1.ApplicationSpec) { s.Environment = map[string]string{"CACHE": "on"} },
+			func(s *appv1alpha1.ApplicationSpec) { s.Environment = map[string]string{"CACHE": "on", "API": "v2"} },
+		},
+		{
+			"other fields are replaced",
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Replicas = 3 },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Port = 8080 },
+			func(s *appv1alpha1.ApplicationSpec) { s.Processes[0].Port = 8080 },
+		},
+	}
+
+	for _, tt := range mergeTests {
+		existing, desired, wanted := base.DeepCopy(), base.DeepCopy(), base.DeepCopy()
+		tt.existing(existing)
+		tt.template(desired)
+		tt.wanted(wanted)
+
+		if spec := mergeSpec(existing, desired, &base); !reflect.DeepEqual(&spec, wanted) {
+			t.Errorf("%s: mergeSpec() got %#v, wanted %#v", tt.name, spec, *wanted)
+		}
+	}
+}
+
+func TestMergeSpecWithoutAppliedSpec(t *testing.T) {
+	existing := &appv1alpha1.ApplicationSpec{Environment: map[string]string{"API": "v2"}}
+	desired := &appv1alpha1.ApplicationSpec{Environment: map[string]string{"LOG_LEVEL": "debug"}}
+
+	if spec := mergeSpec(existing, desired, nil); !reflect.DeepEqual(&spec, desired) {
+		t.Fatalf("mergeSpec() got %#v, wanted %#v", spec, *desired)
+	}
+}
+
+func TestMergeSpecWithChangedOverlayReplicas(t *testing.T) {
+	base := appv1alpha1.ApplicationSpec{
+		Processes: []appv1alpha1.ProcessSpec{{Name: "web", Image: "example/web:v1", Replicas: 1}},
+	}
+	overlay := func(replicas int32) *appv1alpha1.ApplicationSpec {
+		spec := base.DeepCopy()
+		o := appv1alpha1.ApplicationOverlay{
+			Name:      "production",
+			Processes: []appv1alpha1.ProcessOverlay{{Name: "web", Replicas: &replicas}},
+		}
+		if err := applyOverlay(spec, o); err != nil {
+			t.Fatalf("failed to apply overlay: %s", err)
+		}
+		return spec
+	}
+	applied, desired := overlay(2), overlay(5)
+	existing := applied.DeepCopy()
+	mirrored := int32(2)
+	existing.Replicas = &mirrored
+
+	spec := mergeSpec(existing, desired, applied)
+
+	if spec.Replicas != nil {
+		t.Fatalf("mergeSpec() got replicas %d, wanted them reset", *spec.Replicas)
+	}
+	if r := spec.Processes[0].Replicas; r != 5 {
+		t.Fatalf("mergeSpec() got primary process replicas %d, wanted 5", r)
+	}
+}
@@ -0,0 +1,99 @@
+package applicationtemplate
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+// templateLabel identifies the template that generated an Application.
+const templateLabel = "app.bigkevmcd.com/template"
+
+// applicationsFromTemplate makes an Application for each of the template's
+// targets, with the target's overlay applied to the base spec.
+func applicationsFromTemplate(t *appv1alpha1.ApplicationTemplate) ([]*appv1alpha1.Application, error) {
+	overlays := map[string]appv1alpha1.ApplicationOverlay{}
+	for _, o := range t.Spec.Overlays {
+		overlays[o.Name] = o
+	}
+
+	apps := []*appv1alpha1.Application{}
+	for _, target := range t.Spec.Targets {
+		app := &appv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      t.Name,
+				Namespace: target.Namespace,
+				Labels:    labelsForTemplate(t),
+			},
+			Spec: *t.Spec.Spec.DeepCopy(),
+		}
+		if target.Overlay != "" {
+			o, ok := overlays[target.Overlay]
+			if !ok {
+				return nil, fmt.Errorf("target %s uses unknown overlay %s", target.Namespace, target.Overlay)
+			}
+			err := applyOverlay(&app.Spec, o)
+			if err != nil {
+				return nil, err
+			}
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// applyOverlay changes the spec with the overlay.
+func applyOverlay(spec *appv1alpha1.ApplicationSpec, o appv1alpha1.ApplicationOverlay) error {
+	if len(o.Environment) > 0 && spec.Environment == nil {
+		spec.Environment = map[string]string{}
+	}
+	for k, v := range o.Environment {
+		spec.Environment[k] = v
+	}
+	for _, po := range o.Processes {
+		p := findProcess(spec, po.Name)
+		if p == nil {
+			return fmt.Errorf("overlay %s changes unknown process %s", o.Name, po.Name)
+		}
+		if po.Replicas != nil {
+			p.Replicas = *po.Replicas
+		}
+		if po.Tag != "" {
+			p.Image = replaceTag(p.Image, po.Tag)
+		}
+	}
+	return nil
+}
+
+func findProcess(spec *appv1alpha1.ApplicationSpec, name string) *appv1alpha1.ProcessSpec {
+	for i := range spec.Processes {
+		if spec.Processes[i].Name == name {
+			return &spec.Processes[i]
+		}
+	}
+	return nil
+}
+
+// replaceTag replaces the tag or digest of an image reference.
+func replaceTag(image, tag string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i != -1 && !strings.Contains(image[i:], "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
+
+// labelsForTemplate returns the labels for the generated Applications.
+func labelsForTemplate(t *appv1alpha1.ApplicationTemplate) map[string]string {
+	labels := map[string]string{}
+	for k, v := range t.Spec.Labels {
+		labels[k] = v
+	}
+	labels[templateLabel] = t.Name
+	return labels
+}
@@ -0,0 +1,100 @@
+package applicationtemplate
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const testTemplateName = "test-template"
+
+func TestApplicationsFromTemplate(t *testing.T) {
+	apps, err := applicationsFromTemplate(makeTestTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(apps); l != 2 {
+		t.Fatalf("applicationsFromTemplate() got %d Applications, wanted 2", l)
+	}
+	dev, prod := apps[0], apps[1]
+	if dev.Namespace != "dev" || dev.Name != testTemplateName {
+		t.Fatalf("got Application %s/%s, wanted dev/%s", dev.Namespace, dev.Name, testTemplateName)
+	}
+	if !reflect.DeepEqual(dev.Spec, makeTestTemplate().Spec.Spec) {
+		t.Fatalf("got spec %#v, wanted the base spec", dev.Spec)
+	}
+	wantedEnv := map[string]string{"LOG_LEVEL": "warn", "CACHE": "on"}
+	if !reflect.DeepEqual(prod.Spec.Environment, wantedEnv) {
+		t.Fatalf("got environment %#v, wanted %#v", prod.Spec.Environment, wantedEnv)
+	}
+	web := prod.Spec.Processes[0]
+	if web.Replicas != 10 {
+		t.Fatalf("got %d replicas, wanted 10", web.Replicas)
+	}
+	if web.Image != "example/web:v2" {
+		t.Fatalf("got image %s, wanted example/web:v2", web.Image)
+	}
+	if v := prod.Labels[templateLabel]; v != testTemplateName {
+		t.Fatalf("got template label %q, wanted %q", v, testTemplateName)
+	}
+}
+
+func TestApplicationsFromTemplateWithUnknownOverlay(t *testing.T) {
+	template := makeTestTemplate()
+	template.Spec.Targets[0].Overlay = "staging"
+
+	if _, err := applicationsFromTemplate(template); err == nil {
+		t.Fatal("applicationsFromTemplate() got nil, wanted an error")
+	}
+}
+
+func TestReplaceTag(t *testing.T) {
+	tagTests := []struct {
+		image string
+		want  string
+	}{
+		{"example/web", "example/web:v2"},
+		{"example/web:v1", "example/web:v2"},
+		{"registry:5000/example/web", "registry:5000/example/web:v2"},
+		{"registry:5000/example/web:v1", "registry:5000/example/web:v2"},
+		{"example/web@sha256:abcdef", "example/web:v2"},
+	}
+
+	for _, tt := range tagTests {
+		if got := replaceTag(tt.image, "v2"); got != tt.want {
+			t.Errorf("replaceTag(%q) got %q, wanted %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func makeTestTemplate() *appv1alpha1.ApplicationTemplate {
+	replicas := int32(10)
+	return &appv1alpha1.ApplicationTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: testTemplateName},
+		Spec: appv1alpha1.ApplicationTemplateSpec{
+			Spec: appv1alpha1.ApplicationSpec{
+				Environment: map[string]string{"LOG_LEVEL": "debug", "CACHE": "on"},
+				Processes: []appv1alpha1.ProcessSpec{
+					{Name: "web", Image: "example/web:v1", Port: 8080, Replicas: 1},
+				},
+			},
+			Overlays: []appv1alpha1.ApplicationOverlay{
+				{
+					Name:        "production",
+					Environment: map[string]string{"LOG_LEVEL": "warn"},
+					Processes: []appv1alpha1.ProcessOverlay{
+						{Name: "web", Replicas: &replicas, Tag: "v2"},
+					},
+				},
+			},
+			Targets: []appv1alpha1.ApplicationTarget{
+				{Namespace: "dev"},
+				{Namespace: "prod", Overlay: "production"},
+			},
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package promotion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	defaultTimeout = time.Second * 600
+
+	// maxHistory is the number of completed promotions that are kept.
+	maxHistory = 10
+
+	// promoteToAnnotation lists the namespaces that Promotions can copy an
+	// Application's images and environment to, separated by commas.
+	promoteToAnnotation = "app.bigkevmcd.com/promote-to"
+)
+
+// applicationName returns the name of a referenced Application, which
+// defaults to the namespace of the Promotion.
+//
+// validateTarget ensures that the Target is in the Promotion's namespace.
+func applicationName(p *appv1alpha1.Promotion, ref appv1alpha1.ApplicationReference) types.NamespacedName {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = p.Namespace
+	}
+	return types.NamespacedName{Name: ref.Name, Namespace: namespace}
+}
+
+// validateTarget returns an error if the Target is in a different namespace
+// to the Promotion, as the Promotion would let anyone who can create
+// Promotions change Applications in other namespaces.
+func validateTarget(p *appv1alpha1.Promotion) error {
+	if ns := p.Spec.Target.Namespace; ns != "" && ns != p.Namespace {
+		return fmt.Errorf("target Application %s must be in the namespace %s, not %s", p.Spec.Target.Name, p.Namespace, ns)
+	}
+	return nil
+}
+
+// validateSource returns an error if the Source is in a different namespace
+// to the Promotion, and hasn't allowed promotions to it, as the Promotion
+// would let anyone who can create Promotions read the Source's environment.
+func validateSource(p *appv1alpha1.Promotion, source *appv1alpha1.Application) error {
+	if source.Namespace == p.Namespace {
+		return nil
+	}
+	for _, ns := range strings.Split(source.Annotations[promoteToAnnotation], ",") {
+		if strings.TrimSpace(ns) == p.Namespace {
+			return nil
+		}
+	}
+	return fmt.Errorf("source Application %s in the namespace %s doesn't allow promotions to %s", source.Name, source.Namespace, p.Namespace)
+}
+
+// promotedValues returns the images of the Source's processes that the Target
+// also has, and the Source's values for the Promotion's environment keys.
+func promotedValues(p *appv1alpha1.Promotion, source, target *appv1alpha1.Application) (map[string]string, map[string]string) {
+	images := map[string]string{}
+	for _, process := range target.Spec.Processes {
+		if image, ok := source.Status.Images[process.Name]; ok {
+			images[process.Name] = image
+		}
+	}
+	env := map[string]string{}
+	for _, k := range p.Spec.EnvironmentKeys {
+		if v, ok := source.Spec.Environment[k]; ok {
+			env[k] = v
+		}
+	}
+	return images, env
+}
+
+// applyPromotion changes the Target's spec to use the images and environment,
+// returning false if it already does.
+func applyPromotion(target *appv1alpha1.Application, images, env map[string]string) bool {
+	changed := false
+	for i := range target.Spec.Processes {
+		process := &target.Spec.Processes[i]
+		if image, ok := images[process.Name]; ok && process.Image != image {
+			process.Image = image
+			changed = true
+		}
+	}
+	for k, v := range env {
+		if current, ok := target.Spec.Environment[k]; ok && current == v {
+			continue
+		}
+		if target.Spec.Environment == nil {
+			target.Spec.Environment = map[string]string{}
+		}
+		target.Spec.Environment[k] = v
+		changed = true
+	}
+	return changed
+}
+
+// promotionOutcome returns the result of the promotion in progress, or an
+// empty result if it hasn't finished.
+//
+// The promotion only succeeds once the Target is Ready and running the
+// promoted images, the Ready condition may be left over from before the
+// promotion, for example if the Target is paused.
+func promotionOutcome(p *appv1alpha1.Promotion, target *appv1alpha1.Application, now time.Time) (appv1alpha1.PromotionResult, string) {
+	current := p.Status.Current
+	if target.Status.ObservedGeneration >= current.TargetGeneration {
+		if isTrue(target, appv1alpha1.ApplicationReady) && runsImages(target, current.Images) {
+			return appv1alpha1.PromotionSucceeded, ""
+		}
+		for _, t := range []appv1alpha1.ApplicationConditionType{appv1alpha1.ApplicationInvalid, appv1alpha1.ApplicationReleaseFailed, appv1alpha1.ApplicationRolloutStuck} {
+			if c := findCondition(target, t); c != nil && c.Status == corev1.ConditionTrue {
+				return appv1alpha1.PromotionFailed, fmt.Sprintf("target has condition %s: %s", t, c.Message)
+			}
+		}
+	}
+	if now.Sub(current.StartedAt.Time) > timeout(p) {
+		return appv1alpha1.PromotionFailed, "timed out waiting for the target to become Ready"
+	}
+	return "", ""
+}
+
+// runsImages returns true if the Application's pods are running all of the
+// images.
+func runsImages(a *appv1alpha1.Application, images map[string]string) bool {
+	for process, image := range images {
+		if a.Status.Images[process] != image {
+			return false
+		}
+	}
+	return true
+}
+
+// completePromotion moves the promotion in progress to the history.
+func completePromotion(p *appv1alpha1.Promotion, result appv1alpha1.PromotionResult, message string, now time.Time) {
+	record := *p.Status.Current
+	completed := metav1.NewTime(now)
+	record.CompletedAt = &completed
+	record.Result = result
+	record.Message = message
+	p.Status.History = append([]appv1alpha1.PromotionRecord{record}, p.Status.History...)
+	if len(p.Status.History) > maxHistory {
+		p.Status.History = p.Status.History[:maxHistory]
+	}
+	p.Status.Current = nil
+}
+
+func timeout(p *appv1alpha1.Promotion) time.Duration {
+	if p.Spec.TimeoutSeconds == nil {
+		return defaultTimeout
+	}
+	return time.Duration(*p.Spec.TimeoutSeconds) * time.Second
+}
+
+func findCondition(a *appv1alpha1.Application, t appv1alpha1.ApplicationConditionType) *appv1alpha1.ApplicationCondition {
+	for i := range a.Status.Conditions {
+		if a.Status.Conditions[i].Type == t {
+			return &a.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func isTrue(a *appv1alpha1.Application, t appv1alpha1.ApplicationConditionType) bool {
+	c := findCondition(a, t)
+	return c != nil && c.Status == corev1.ConditionTrue
+}
@@ -0,0 +1,187 @@
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/go-logr/logr"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+var log = logf.Log.WithName("controller_promotion")
+
+// promotionRequeueDelay is how often a promotion in progress is checked for
+// timing out.
+const promotionRequeueDelay = time.Second * 30
+
+// Add creates a new Promotion Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler.
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcilePromotion{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler.
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New("promotion-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &appv1alpha1.Promotion{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &appv1alpha1.Application{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: referencingPromotions(mgr.GetClient()),
+	})
+}
+
+// ReconcilePromotion reconciles a Promotion object.
+type ReconcilePromotion struct {
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile promotes the running images of the Source Application to the
+// Target when they change, and records the outcome once the Target has
+// rolled them out.
+func (r *ReconcilePromotion) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling Promotion")
+
+	promotion := &appv1alpha1.Promotion{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, promotion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status := promotion.Status.DeepCopy()
+	res, err := r.reconcilePromotion(promotion, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if equality.Semantic.DeepEqual(&promotion.Status, status) {
+		return res, nil
+	}
+	return res, r.client.Status().Update(context.TODO(), promotion)
+}
+
+func (r *ReconcilePromotion) reconcilePromotion(p *appv1alpha1.Promotion, logger logr.Logger) (reconcile.Result, error) {
+	if err := validateTarget(p); err != nil {
+		p.Status.Message = err.Error()
+		return reconcile.Result{}, nil
+	}
+	source, err := r.getApplication(applicationName(p, p.Spec.Source))
+	if err != nil || source == nil {
+		p.Status.Message = fmt.Sprintf("source Application %s not found", p.Spec.Source.Name)
+		return reconcile.Result{}, err
+	}
+	if err := validateSource(p, source); err != nil {
+		p.Status.Message = err.Error()
+		return reconcile.Result{}, nil
+	}
+	target, err := r.getApplication(applicationName(p, p.Spec.Target))
+	if err != nil || target == nil {
+		p.Status.Message = fmt.Sprintf("target Application %s not found", p.Spec.Target.Name)
+		return reconcile.Result{}, err
+	}
+	p.Status.Message = ""
+
+	if p.Status.Current != nil {
+		now := time.Now()
+		result, message := promotionOutcome(p, target, now)
+		if result == "" {
+			return reconcile.Result{RequeueAfter: promotionRequeueDelay}, nil
+		}
+		logger.Info("Promotion completed", "Result", result, "Message", message)
+		completePromotion(p, result, message, now)
+		return reconcile.Result{}, nil
+	}
+
+	if !isTrue(source, appv1alpha1.ApplicationReady) {
+		p.Status.Message = "waiting for the source Application to become Ready"
+		return reconcile.Result{}, nil
+	}
+	images, env := promotedValues(p, source, target)
+	if len(images) == 0 {
+		p.Status.Message = "the source Application has no running images to promote"
+		return reconcile.Result{}, nil
+	}
+	if !applyPromotion(target, images, env) {
+		return reconcile.Result{}, nil
+	}
+
+	logger.Info("Promoting images", "Target.Namespace", target.Namespace, "Target.Name", target.Name)
+	err = r.client.Update(context.TODO(), target)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	p.Status.Current = &appv1alpha1.PromotionRecord{
+		Images:           images,
+		Environment:      env,
+		TargetGeneration: target.Generation,
+		StartedAt:        metav1.Now(),
+	}
+	return reconcile.Result{RequeueAfter: promotionRequeueDelay}, nil
+}
+
+// getApplication returns the Application, or nil if it doesn't exist.
+func (r *ReconcilePromotion) getApplication(name types.NamespacedName) (*appv1alpha1.Application, error) {
+	app := &appv1alpha1.Application{}
+	err := r.client.Get(context.TODO(), name, app)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// referencingPromotions returns a mapper from an Application to the
+// Promotions that have it as their Source or Target.
+func referencingPromotions(cl client.Client) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		promotions := &appv1alpha1.PromotionList{}
+		err := cl.List(context.TODO(), &client.ListOptions{}, promotions)
+		if err != nil {
+			log.Error(err, "failed to list Promotions")
+			return nil
+		}
+		name := types.NamespacedName{Name: o.Meta.GetName(), Namespace: o.Meta.GetNamespace()}
+		requests := []reconcile.Request{}
+		for i := range promotions.Items {
+			p := &promotions.Items[i]
+			if applicationName(p, p.Spec.Source) == name || applicationName(p, p.Spec.Target) == name {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace},
+				})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,269 @@
+package promotion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/bigkevmcd/applications/pkg/apis/app/v1alpha1"
+)
+
+const (
+	testNamespace     = "test-namespace"
+	testPromotionName = "staging-to-production"
+	testDigest        = "example/web@sha256:0123456789abcdef"
+)
+
+var _ reconcile.Reconciler = &ReconcilePromotion{}
+
+func TestReconcilePromotesImages(t *testing.T) {
+	r, cl := createPromotionReconciler(t, makeTestPromotion(), makeSource(), makeTarget())
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	target := getApplication(t, cl, "production")
+	if image := target.Spec.Processes[0].Image; image != testDigest {
+		t.Fatalf("got target image %s, wanted %s", image, testDigest)
+	}
+	if v := target.Spec.Environment["FEATURE_FLAGS"]; v != "new-checkout" {
+		t.Fatalf("got FEATURE_FLAGS %q, wanted %q", v, "new-checkout")
+	}
+	if _, ok := target.Spec.Environment["DATABASE_URL"]; ok {
+		t.Fatal("DATABASE_URL was promoted, it's not in the environment keys")
+	}
+	p := getPromotion(t, cl)
+	if p.Status.Current == nil {
+		t.Fatal("promotion is not in progress")
+	}
+}
+
+func TestReconcileRecordsPromotionResult(t *testing.T) {
+	resultTests := []struct {
+		condition appv1alpha1.ApplicationConditionType
+		want      appv1alpha1.PromotionResult
+	}{
+		{appv1alpha1.ApplicationReady, appv1alpha1.PromotionSucceeded},
+		{appv1alpha1.ApplicationRolloutStuck, appv1alpha1.PromotionFailed},
+	}
+
+	for _, tt := range resultTests {
+		r, cl := createPromotionReconciler(t, makeTestPromotion(), makeSource(), makeTarget())
+		_, err := r.Reconcile(makeRequest())
+		fatalIfError(t, "failed to reconcile", err)
+
+		target := getApplication(t, cl, "production")
+		target.Status.Conditions = []appv1alpha1.ApplicationCondition{
+			{Type: tt.condition, Status: corev1.ConditionTrue},
+		}
+		target.Status.Images = map[string]string{"web": testDigest}
+		fatalIfError(t, "failed to update target", cl.Update(context.TODO(), target))
+		_, err = r.Reconcile(makeRequest())
+
+		fatalIfError(t, "failed to reconcile", err)
+		p := getPromotion(t, cl)
+		if p.Status.Current != nil {
+			t.Fatalf("%s: promotion is still in progress", tt.condition)
+		}
+		if l := len(p.Status.History); l != 1 {
+			t.Fatalf("%s: got %d history records, wanted 1", tt.condition, l)
+		}
+		if result := p.Status.History[0].Result; result != tt.want {
+			t.Errorf("%s: got result %s, wanted %s", tt.condition, result, tt.want)
+		}
+	}
+}
+
+func TestReconcileWaitsForPromotedImages(t *testing.T) {
+	target := makeTarget()
+	target.Status.Conditions = []appv1alpha1.ApplicationCondition{
+		{Type: appv1alpha1.ApplicationReady, Status: corev1.ConditionTrue},
+	}
+	target.Status.Images = map[string]string{"web": "example/web@sha256:fedcba9876543210"}
+	r, cl := createPromotionReconciler(t, makeTestPromotion(), makeSource(), target)
+	_, err := r.Reconcile(makeRequest())
+	fatalIfError(t, "failed to reconcile", err)
+
+	_, err = r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	if p := getPromotion(t, cl); p.Status.Current == nil {
+		t.Fatalf("promotion completed with result %s before the target ran the images", p.Status.History[0].Result)
+	}
+}
+
+func TestReconcileRejectsTargetInAnotherNamespace(t *testing.T) {
+	p := makeTestPromotion()
+	p.Spec.Target.Namespace = "other-namespace"
+	target := makeTarget()
+	target.Namespace = "other-namespace"
+	r, cl := createPromotionReconciler(t, p, makeSource(), target)
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	unchanged := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get target", cl.Get(context.TODO(), types.NamespacedName{Name: "production", Namespace: "other-namespace"}, unchanged))
+	if image := unchanged.Spec.Processes[0].Image; image != "example/web:v1" {
+		t.Fatalf("got target image %s, wanted it unchanged", image)
+	}
+	if p := getPromotion(t, cl); p.Status.Message == "" {
+		t.Fatal("got no status message for the rejected target")
+	}
+}
+
+func TestReconcileRejectsSourceInAnotherNamespace(t *testing.T) {
+	sourceTests := []struct {
+		promoteTo string
+		allowed   bool
+	}{
+		{"", false},
+		{"staging-namespace", false},
+		{"staging-namespace, " + testNamespace, true},
+	}
+
+	for _, tt := range sourceTests {
+		p := makeTestPromotion()
+		p.Spec.Source.Namespace = "staging-namespace"
+		source := makeSource()
+		source.Namespace = "staging-namespace"
+		if tt.promoteTo != "" {
+			source.Annotations = map[string]string{promoteToAnnotation: tt.promoteTo}
+		}
+		r, cl := createPromotionReconciler(t, p, source, makeTarget())
+
+		_, err := r.Reconcile(makeRequest())
+
+		fatalIfError(t, "failed to reconcile", err)
+		target := getApplication(t, cl, "production")
+		if promoted := target.Spec.Environment["FEATURE_FLAGS"] != ""; promoted != tt.allowed {
+			t.Errorf("promote-to %q: got environment %#v, wanted promoted %v", tt.promoteTo, target.Spec.Environment, tt.allowed)
+		}
+		if p := getPromotion(t, cl); (p.Status.Message == "") != tt.allowed {
+			t.Errorf("promote-to %q: got status message %q", tt.promoteTo, p.Status.Message)
+		}
+	}
+}
+
+func TestReconcileWaitsForReadySource(t *testing.T) {
+	source := makeSource()
+	source.Status.Conditions = nil
+	r, cl := createPromotionReconciler(t, makeTestPromotion(), source, makeTarget())
+
+	_, err := r.Reconcile(makeRequest())
+
+	fatalIfError(t, "failed to reconcile", err)
+	if image := getApplication(t, cl, "production").Spec.Processes[0].Image; image != "example/web:v1" {
+		t.Fatalf("got target image %s, wanted it unchanged", image)
+	}
+}
+
+func TestPromotionOutcomeTimesOut(t *testing.T) {
+	timeoutSeconds := int32(60)
+	p := makeTestPromotion()
+	p.Spec.TimeoutSeconds = &timeoutSeconds
+	p.Status.Current = &appv1alpha1.PromotionRecord{StartedAt: metav1.NewTime(time.Now().Add(-time.Minute * 2))}
+
+	result, _ := promotionOutcome(p, makeTarget(), time.Now())
+
+	if result != appv1alpha1.PromotionFailed {
+		t.Fatalf("got result %q, wanted %q", result, appv1alpha1.PromotionFailed)
+	}
+}
+
+func TestCompletePromotionLimitsHistory(t *testing.T) {
+	p := makeTestPromotion()
+	p.Status.History = make([]appv1alpha1.PromotionRecord, maxHistory)
+	p.Status.Current = &appv1alpha1.PromotionRecord{Images: map[string]string{"web": testDigest}}
+
+	completePromotion(p, appv1alpha1.PromotionSucceeded, "", time.Now())
+
+	if l := len(p.Status.History); l != maxHistory {
+		t.Fatalf("got %d history records, wanted %d", l, maxHistory)
+	}
+	if image := p.Status.History[0].Images["web"]; image != testDigest {
+		t.Fatalf("got %s in the latest record, wanted %s", image, testDigest)
+	}
+}
+
+func makeTestPromotion() *appv1alpha1.Promotion {
+	return &appv1alpha1.Promotion{
+		ObjectMeta: metav1.ObjectMeta{Name: testPromotionName, Namespace: testNamespace},
+		Spec: appv1alpha1.PromotionSpec{
+			Source:          appv1alpha1.ApplicationReference{Name: "staging"},
+			Target:          appv1alpha1.ApplicationReference{Name: "production"},
+			EnvironmentKeys: []string{"FEATURE_FLAGS"},
+		},
+	}
+}
+
+func makeSource() *appv1alpha1.Application {
+	app := makeApplication("staging")
+	app.Spec.Processes[0].Image = "example/web:v2"
+	app.Spec.Environment = map[string]string{"FEATURE_FLAGS": "new-checkout", "DATABASE_URL": "postgres://staging"}
+	app.Status.Images = map[string]string{"web": testDigest}
+	app.Status.Conditions = []appv1alpha1.ApplicationCondition{
+		{Type: appv1alpha1.ApplicationReady, Status: corev1.ConditionTrue},
+	}
+	return app
+}
+
+func makeTarget() *appv1alpha1.Application {
+	return makeApplication("production")
+}
+
+func makeApplication(name string) *appv1alpha1.Application {
+	return &appv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec: appv1alpha1.ApplicationSpec{
+			Processes: []appv1alpha1.ProcessSpec{
+				{Name: "web", Image: "example/web:v1", Port: 8080, Replicas: 1},
+			},
+		},
+	}
+}
+
+func createPromotionReconciler(t *testing.T, obj ...runtime.Object) (ReconcilePromotion, client.Client) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Kubernetes types to scheme: %s", err)
+	}
+	if err := appv1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add Application types to scheme: %s", err)
+	}
+	cl := fake.NewFakeClientWithScheme(s, obj...)
+	return ReconcilePromotion{client: cl, scheme: s}, cl
+}
+
+func getApplication(t *testing.T, cl client.Client, name string) *appv1alpha1.Application {
+	t.Helper()
+	app := &appv1alpha1.Application{}
+	fatalIfError(t, "failed to get application", cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, app))
+	return app
+}
+
+func getPromotion(t *testing.T, cl client.Client) *appv1alpha1.Promotion {
+	t.Helper()
+	p := &appv1alpha1.Promotion{}
+	fatalIfError(t, "failed to get promotion", cl.Get(context.TODO(), types.NamespacedName{Name: testPromotionName, Namespace: testNamespace}, p))
+	return p
+}
+
+func makeRequest() reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: testPromotionName, Namespace: testNamespace}}
+}
+
+func fatalIfError(t *testing.T, msg string, err error) {
+	if err != nil {
+		t.Fatalf("%s: %s", msg, err)
+	}
+}